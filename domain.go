@@ -2,40 +2,45 @@ package main
 
 import (
 	. "dyiui/internal/layout"
+	. "dyiui/internal/ui"
 )
 
 // this file represents how users
 // would use the library
 
 type AppState struct {
-	Tab int
+	Tab  int
+	Name string
 }
 
-var appState AppState = AppState{}
-
 func RenderUI(
 	ui *UI,
 ) {
-	Begin(ui.Context.Width, ui.Context.Height)
+	ui.Begin(ui.Context.Width, ui.Context.Height)
+
+	// kept across frames by the context
+	appState := GetState[AppState](ui.Context, ui.GetID("##app"))
 
 	if appState.Tab == 0 {
-		RenderFirstTab(ui)
+		RenderFirstTab(ui, appState)
 	} else {
-		RenderSecondTab(ui)
+		RenderSecondTab(ui, appState)
 	}
 
-	End()
+	ui.End()
 }
 
-func RenderFirstTab(ui *UI) {
+func RenderFirstTab(ui *UI, appState *AppState) {
 	clicked := ui.DrawButton("first")
 
+	ui.InputText("Name", &appState.Name)
+
 	if clicked {
 		appState.Tab = 1
 	}
 }
 
-func RenderSecondTab(ui *UI) {
+func RenderSecondTab(ui *UI, appState *AppState) {
 	clicked := ui.DrawButton("Go to first page")
 
 	if clicked {
@@ -7,11 +7,9 @@ require (
 	github.com/danielgatis/go-freetype v0.0.0-20200926043333-689a6dd62194
 	github.com/go-gl/gl v0.0.0-20231021071112-07e5d0ea2e71
 	github.com/go-gl/glfw/v3.3/glfw v0.0.0-20221017161538-93cebf72946b
+	golang.org/x/text v0.13.0
 )
 
-require (
-	golang.org/x/image v0.12.0 // indirect
-	golang.org/x/text v0.13.0 // indirect
-)
+require golang.org/x/image v0.12.0 // indirect
 
 replace github.com/danielgatis/go-freetype => ../freetype
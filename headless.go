@@ -0,0 +1,35 @@
+package main
+
+import (
+	"dyiui/internal/soft"
+	"dyiui/internal/text"
+	. "dyiui/internal/layout"
+	. "dyiui/internal/ui"
+)
+
+// Renders a single frame with the software backend
+// and writes it into a png, without opening a window
+func RenderHeadless(path string, width int, height int) error {
+	font, err := text.GetSomeFont()
+	if err != nil {
+		return err
+	}
+
+	renderer := soft.NewRenderer(width, height, font)
+	defer renderer.Fonts.Close()
+
+	context := &Context{
+		Width:        uint32(width),
+		Height:       uint32(height),
+		PointerState: NewPointerState(),
+	}
+
+	renderer.BeginFrame()
+	ui := NewUI(context, renderer)
+
+	RenderUI(&ui)
+
+	renderer.FinishFrame(ui.DrawList)
+
+	return writePng(path, renderer.Target)
+}
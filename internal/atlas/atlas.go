@@ -0,0 +1,254 @@
+package atlas
+
+import (
+	"dyiui/internal/lru"
+	"dyiui/internal/types"
+	"errors"
+	"image"
+	"image/color"
+	"image/draw"
+
+	"github.com/benoitkugler/textlayout/fonts"
+)
+
+type AtlasId = int
+
+// AtlasRepo
+
+type AtlasRepo struct {
+    entries map[AtlasId]*Atlas
+}
+
+func (at *AtlasRepo) Get(id AtlasId) *Atlas {
+    return at.entries[id]
+}
+
+func (at *AtlasRepo) Add(id AtlasId, size int32) *Atlas {
+    if at.entries == nil {
+        at.entries = make(map[AtlasId]*Atlas)
+    }
+
+    a := NewAtlas(size)
+    at.entries[id] = a
+
+    return a
+}
+
+
+// Atlas
+
+// Identifies a rasterized glyph. The same glyph of
+// another font or size has a different bitmap.
+type GlyphKey struct {
+    Font int
+    // in pixels
+    Size float32
+    GID fonts.GID
+    // a distance field rasterized at SDF_SIZE,
+    // which is shared by all sizes
+    SDF bool
+}
+
+// Where a glyph is stored
+type Slot struct {
+    // -1 for glyphs without pixels, like spaces
+    Page int
+    // in pixels of the page
+    Rect types.Quad
+    // of the bitmap relative to the pen position on the
+    // baseline, y grows downwards. Kept along with the slot,
+    // so glyphs in the atlas need not be rasterized again.
+    Bounds image.Rectangle
+}
+
+func (s Slot) IsEmpty() bool {
+    return s.Page < 0
+}
+
+type CacheEntry = Slot
+
+// Glyphs, which are kept before the least recently
+// used ones are dropped from the cache and their slot
+// is handed out again
+const CACHED_GLYPHS = 4096
+
+var ErrGlyphTooLarge = errors.New("glyph does not fit into an atlas page")
+
+// A square texture, glyphs are packed into
+type Page struct {
+    // not premultiplied, which is what the shaders expect
+    Image *image.NRGBA
+    Packer *ShelfPacker
+    // set, when the image changed since the backend
+    // last mirrored it
+    Dirty bool
+}
+
+func NewPage(size int32) *Page {
+    return &Page {
+        Image: image.NewNRGBA(image.Rect(0, 0, int(size), int(size))),
+        Packer: NewShelfPacker(int(size), int(size)),
+    }
+}
+
+// Keeps the rasterized glyphs on the cpu side,
+// so backends can either sample them directly or
+// mirror them into textures, one per page.
+// Pages are added, when the existing ones are full.
+type Atlas struct {
+    Pages []*Page
+    PageSize int32
+    Cache *lru.LRUCache[GlyphKey, CacheEntry]
+    // rect on the first page filled with opaque white
+    White types.Quad
+}
+
+func NewAtlas(size int32) *Atlas {
+    return newAtlas(size, CACHED_GLYPHS)
+}
+
+func newAtlas(size int32, cachedGlyphs uint) *Atlas {
+    a := &Atlas {
+        PageSize: size,
+        Cache: lru.NewLRUCache[GlyphKey, CacheEntry](cachedGlyphs),
+    }
+
+    // the slots of dropped glyphs are reused for new ones. They
+    // are cleared, since smaller glyphs leave parts of them as is,
+    // which would be sampled along with their edges otherwise.
+    a.Cache.OnEvict = func(key GlyphKey, slot CacheEntry) {
+        if slot.IsEmpty() {
+            return
+        }
+
+        page := a.Pages[slot.Page]
+        freed := page.Packer.Free(toRect(slot.Rect))
+        draw.Draw(page.Image, freed, image.Transparent, image.Point{}, draw.Src)
+        page.Dirty = true
+    }
+
+    // reserve a white rect, so untextured quads
+    // can be drawn with the first page bound as well
+    white, err := a.alloc(3, 3)
+    if err != nil {
+        panic(err)
+    }
+
+    a.White = white.Rect
+    page := a.Pages[white.Page]
+    draw.Draw(page.Image, toRect(a.White), image.White, image.Point{}, draw.Src)
+    page.Dirty = true
+
+    return a
+}
+
+// Where the glyph is stored, if it is cached
+func (at *Atlas) Lookup(key GlyphKey) (Slot, bool) {
+    if e := at.Cache.Get(key); e != nil {
+        return *e, true
+    }
+    return Slot{}, false
+}
+
+// Looks up where the glyph is stored. If it is not cached yet,
+// room for a bitmap of the given bounds is reserved, which is to
+// be filled with Insert. Empty bounds take no room, but are cached
+// all the same. The second result tells, whether it was cached.
+func (at *Atlas) GetSlot(key GlyphKey, bounds image.Rectangle) (Slot, bool, error) {
+    if slot, ok := at.Lookup(key); ok {
+        return slot, true, nil
+    }
+
+    slot, err := at.Reserve(key, bounds)
+    return slot, false, err
+}
+
+// Reserves room for a glyph, which is not cached yet
+func (at *Atlas) Reserve(key GlyphKey, bounds image.Rectangle) (Slot, error) {
+    // evict first, so the freed slot can be taken right away
+    if at.Cache.IsFull() {
+        at.Cache.EvictOldest()
+    }
+
+    slot := Slot{ Page: -1 }
+    if !bounds.Empty() {
+        var err error
+        slot, err = at.alloc(bounds.Dx(), bounds.Dy())
+        if err != nil {
+            return Slot{}, err
+        }
+    }
+
+    slot.Bounds = bounds
+    at.Cache.Store(key, slot)
+    return slot, nil
+}
+
+// Packs the rect into the first page with room,
+// or into a new one, if all are full
+func (at *Atlas) alloc(w, h int) (Slot, error) {
+    for i, p := range at.Pages {
+        if r, ok := p.Packer.Alloc(w, h); ok {
+            return Slot{ Page: i, Rect: toQuad(r) }, nil
+        }
+    }
+
+    p := NewPage(at.PageSize)
+    r, ok := p.Packer.Alloc(w, h)
+    if !ok {
+        return Slot{}, ErrGlyphTooLarge
+    }
+
+    at.Pages = append(at.Pages, p)
+    return Slot{ Page: len(at.Pages) - 1, Rect: toQuad(r) }, nil
+}
+
+// Copies a rasterized glyph into the slot.
+// The glyph bitmaps carry their coverage in the red channel,
+// which is stored as alpha of a white pixel, so glyphs can be
+// tinted by multiplying with the text color.
+func (at *Atlas) Insert(slot Slot, i *image.RGBA) {
+    page := at.Pages[slot.Page]
+    pos := slot.Rect
+
+    b := i.Rect
+    for y := b.Min.Y; y < b.Max.Y; y++ {
+        for x := b.Min.X; x < b.Max.X; x++ {
+            c := i.RGBAAt(x, y)
+            page.Image.SetNRGBA(
+                int(pos.X) + x - b.Min.X,
+                int(pos.Y) + y - b.Min.Y,
+                color.NRGBA{ 255, 255, 255, c.R },
+            )
+        }
+    }
+
+    page.Dirty = true
+}
+
+// Maps a rect in pixels to texture coordinates,
+// all pages have the same size
+func (at *Atlas) UV(q types.Quad) types.Quad {
+    s := float32(at.PageSize)
+
+    return types.Quad {
+        X: q.X / s,
+        Y: q.Y / s,
+        W: q.W / s,
+        H: q.H / s,
+    }
+}
+
+// Texture coordinates of a single white texel on the first page
+func (at *Atlas) WhiteUV() types.Quad {
+    uv := at.UV(at.White)
+    return types.NewQuad(uv.X + uv.W / 2.0, uv.Y + uv.H / 2.0, 0, 0)
+}
+
+func toRect(q types.Quad) image.Rectangle {
+    return image.Rect(int(q.X), int(q.Y), int(q.X + q.W), int(q.Y + q.H))
+}
+
+func toQuad(r image.Rectangle) types.Quad {
+    return types.NewQuad(float32(r.Min.X), float32(r.Min.Y), float32(r.Dx()), float32(r.Dy()))
+}
@@ -0,0 +1,206 @@
+package atlas
+
+import (
+	"image"
+	"image/draw"
+	"testing"
+
+	"github.com/benoitkugler/textlayout/fonts"
+)
+
+func TestPackedRectsDoNotOverlap(t *testing.T) {
+	p := NewShelfPacker(64, 64)
+	var placed []image.Rectangle
+
+	sizes := [][2]int{{10, 12}, {20, 12}, {5, 30}, {30, 11}, {8, 8}, {16, 28}}
+	for _, s := range sizes {
+		r, ok := p.Alloc(s[0], s[1])
+		if !ok {
+			t.Fatalf("Expected %v to fit", s)
+		}
+
+		if r.Dx() != s[0] || r.Dy() != s[1] {
+			t.Fatalf("Expected rect of size %v, got %v", s, r)
+		}
+
+		// keep the padding when checking for overlaps
+		padded := image.Rect(r.Min.X, r.Min.Y, r.Max.X+GLYPH_PADDING, r.Max.Y+GLYPH_PADDING)
+		for _, o := range placed {
+			if padded.Overlaps(o) {
+				t.Fatalf("%v overlaps %v", r, o)
+			}
+		}
+
+		placed = append(placed, padded)
+	}
+}
+
+func TestPackerReportsFullPage(t *testing.T) {
+	p := NewShelfPacker(32, 32)
+
+	if _, ok := p.Alloc(40, 10); ok {
+		t.Fatalf("Expected rect wider than the page to be rejected")
+	}
+
+	// shelves of 8px below the top padding
+	for i := 0; i < 3; i++ {
+		if _, ok := p.Alloc(30, 6); !ok {
+			t.Fatalf("Expected shelf %d to fit", i)
+		}
+	}
+
+	if _, ok := p.Alloc(30, 6); ok {
+		t.Fatalf("Expected page to be full")
+	}
+}
+
+func TestRejectedRectsTakeNoShelf(t *testing.T) {
+	p := NewShelfPacker(32, 32)
+
+	for i := 0; i < 3; i++ {
+		if _, ok := p.Alloc(32, 20); ok {
+			t.Fatalf("Expected rect, which does not fit next to the padding, to be rejected")
+		}
+	}
+
+	if len(p.shelves) != 0 {
+		t.Fatalf("Expected no shelves to be added, got %d", len(p.shelves))
+	}
+
+	if _, ok := p.Alloc(30, 26); !ok {
+		t.Fatalf("Expected the page to be still empty")
+	}
+}
+
+func TestAtlasAddsPagesWhenFull(t *testing.T) {
+	a := NewAtlas(64)
+
+	for gid := 0; gid < 8; gid++ {
+		key := GlyphKey{Font: 0, Size: 32, GID: fonts.GID(gid)}
+		if _, _, err := a.GetSlot(key, image.Rect(0, 0, 30, 30)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if len(a.Pages) < 2 {
+		t.Fatalf("Expected glyphs to spill into more pages, got %d", len(a.Pages))
+	}
+
+	if _, _, err := a.GetSlot(GlyphKey{GID: 100}, image.Rect(0, 0, 65, 10)); err != ErrGlyphTooLarge {
+		t.Fatalf("Expected glyph larger than a page to be rejected, got %v", err)
+	}
+}
+
+func TestGlyphsAreKeyedBySize(t *testing.T) {
+	a := NewAtlas(256)
+	small := GlyphKey{Font: 0, Size: 16, GID: 1}
+	large := GlyphKey{Font: 0, Size: 32, GID: 1}
+
+	s, cached, _ := a.GetSlot(small, image.Rect(0, 0, 8, 8))
+	if cached {
+		t.Fatalf("Expected first lookup to miss")
+	}
+
+	l, cached, _ := a.GetSlot(large, image.Rect(0, 0, 16, 16))
+	if cached || l == s {
+		t.Fatalf("Expected same glyph of another size to get its own slot")
+	}
+
+	again, cached, _ := a.GetSlot(small, image.Rect(0, 0, 8, 8))
+	if !cached || again != s {
+		t.Fatalf("Expected second lookup to hit the same slot, got %v", again)
+	}
+}
+
+func TestFreedRectsAreReused(t *testing.T) {
+	p := NewShelfPacker(64, 64)
+	a, _ := p.Alloc(10, 10)
+	b, _ := p.Alloc(10, 10)
+	p.Alloc(10, 10)
+
+	p.Free(b)
+	if r, _ := p.Alloc(8, 10); r.Min != b.Min {
+		t.Fatalf("Expected gap at %v to be reused, got %v", b, r)
+	}
+
+	// a rect wider than the gap must not overlap its neighbours
+	p.Free(a)
+	if r, _ := p.Alloc(12, 10); r.Min == a.Min {
+		t.Fatalf("Expected gap of %v to be too narrow for %v", a, r)
+	}
+}
+
+func TestEvictedGlyphsFreeTheirSlot(t *testing.T) {
+	a := newAtlas(64, 1)
+
+	first, _, _ := a.GetSlot(GlyphKey{GID: 1}, image.Rect(0, 0, 30, 30))
+	second, _, _ := a.GetSlot(GlyphKey{GID: 2}, image.Rect(0, 0, 30, 30))
+
+	if second != first {
+		t.Fatalf("Expected slot of evicted glyph to be reused, got %v and %v", first, second)
+	}
+
+	if a.Cache.Stats.Evictions != 1 {
+		t.Fatalf("Expected an eviction, got %+v", a.Cache.Stats)
+	}
+}
+
+func TestEvictedGlyphsAreCleared(t *testing.T) {
+	a := newAtlas(64, 1)
+
+	opaque := image.NewRGBA(image.Rect(0, 0, 30, 30))
+	draw.Draw(opaque, opaque.Rect, image.White, image.Point{}, draw.Src)
+
+	first, _, _ := a.GetSlot(GlyphKey{GID: 1}, opaque.Rect)
+	a.Insert(first, opaque)
+
+	second, _, _ := a.GetSlot(GlyphKey{GID: 2}, image.Rect(0, 0, 20, 20))
+	if second.Rect.X != first.Rect.X || second.Rect.Y != first.Rect.Y {
+		t.Fatalf("Expected the smaller glyph to take the freed slot, got %v", second)
+	}
+
+	// right of and below the smaller glyph, where the first one was
+	page := a.Pages[second.Page].Image
+	for _, p := range []image.Point{{int(second.Rect.X) + 20, int(second.Rect.Y)}, {int(second.Rect.X), int(second.Rect.Y) + 20}} {
+		if alpha := page.NRGBAAt(p.X, p.Y).A; alpha != 0 {
+			t.Fatalf("Expected the padding at %v to be cleared, got alpha %d", p, alpha)
+		}
+	}
+}
+
+func TestDistanceField(t *testing.T) {
+	// a filled square in the middle of the bitmap
+	coverage := image.NewRGBA(image.Rect(0, 0, 20, 20))
+	for y := 5; y < 15; y++ {
+		for x := 5; x < 15; x++ {
+			coverage.Pix[coverage.PixOffset(x, y)] = 255
+		}
+	}
+
+	field := DistanceField(coverage, 4)
+
+	if field.Rect.Dx() != 28 || field.Rect.Dy() != 28 {
+		t.Fatalf("Expected the field to grow by the spread, got %v", field.Rect)
+	}
+
+	at := func(x, y int) uint8 {
+		return field.RGBAAt(x+4, y+4).R
+	}
+
+	if at(10, 10) <= 128 {
+		t.Errorf("Expected the inside above the outline, got %d", at(10, 10))
+	}
+
+	if at(2, 10) >= 128 {
+		t.Errorf("Expected the outside below the outline, got %d", at(2, 10))
+	}
+
+	if at(-4, -4) != 0 {
+		t.Errorf("Expected nothing beyond the spread, got %d", at(-4, -4))
+	}
+
+	// the values fall off towards the outside
+	if !(at(5, 10) > at(4, 10) && at(4, 10) > at(3, 10)) {
+		t.Errorf("Expected falling distances, got %d %d %d", at(5, 10), at(4, 10), at(3, 10))
+	}
+}
@@ -0,0 +1,165 @@
+package atlas
+
+import (
+	"image"
+)
+
+// Empty pixels kept between glyphs, so sampling
+// at the border of one never picks up its neighbour
+const GLYPH_PADDING = 1
+
+// Shelves are rounded up to a multiple of this, so glyphs
+// of similar height end up sharing one
+const SHELF_GRANULARITY = 4
+
+type shelf struct {
+	y      int
+	height int
+	// where the next glyph on the shelf starts
+	x int
+	// gaps left by freed rects, including their padding
+	free []image.Rectangle
+}
+
+// Packs rects of varying size into a page by stacking shelves
+// from top to bottom, each filled from left to right.
+// Glyphs of one font size have similar heights, which makes
+// this waste little space while being cheap to compute.
+type ShelfPacker struct {
+	width   int
+	height  int
+	shelves []shelf
+}
+
+func NewShelfPacker(width, height int) *ShelfPacker {
+	return &ShelfPacker{
+		width:  width,
+		height: height,
+	}
+}
+
+// Reserves a w×h rect, returns false if the page is full
+func (p *ShelfPacker) Alloc(w, h int) (image.Rectangle, bool) {
+	pw := w + GLYPH_PADDING
+	ph := h + GLYPH_PADDING
+
+	// wider than a shelf, rejected before a shelf is added for it
+	if GLYPH_PADDING+pw > p.width {
+		return image.Rectangle{}, false
+	}
+
+	if r, ok := p.reuse(pw, ph); ok {
+		return image.Rect(r.Min.X, r.Min.Y, r.Min.X+w, r.Min.Y+h), true
+	}
+
+	// the shelf wasting the least height
+	best := -1
+	for i, s := range p.shelves {
+		if !fitsShelf(s, ph) || s.x+pw > p.width {
+			continue
+		}
+
+		if best < 0 || s.height < p.shelves[best].height {
+			best = i
+		}
+	}
+
+	if best < 0 {
+		best = p.addShelf(ph)
+		if best < 0 {
+			return image.Rectangle{}, false
+		}
+	}
+
+	s := &p.shelves[best]
+	r := image.Rect(s.x, s.y, s.x+w, s.y+h)
+	s.x += pw
+
+	return r, true
+}
+
+// Makes the rect available again. Gaps are only reused as a whole,
+// by rects, which fit into them, they are not split up. Returns
+// the area freed, which includes the padding and the shelf's
+// height below the rect.
+func (p *ShelfPacker) Free(r image.Rectangle) image.Rectangle {
+	for i := range p.shelves {
+		s := &p.shelves[i]
+		if r.Min.Y != s.y || r.Max.Y > s.y+s.height {
+			continue
+		}
+
+		padded := image.Rect(r.Min.X, s.y, r.Max.X+GLYPH_PADDING, s.y+s.height)
+
+		// the last rect of the shelf, just move back
+		if padded.Max.X == s.x {
+			s.x = padded.Min.X
+			return padded
+		}
+
+		s.free = append(s.free, padded)
+		return padded
+	}
+
+	return image.Rectangle{}
+}
+
+// Takes the narrowest gap the padded rect fits into
+func (p *ShelfPacker) reuse(pw, ph int) (image.Rectangle, bool) {
+	bestShelf, bestGap := -1, -1
+
+	for i, s := range p.shelves {
+		if !fitsShelf(s, ph) {
+			continue
+		}
+
+		for j, gap := range s.free {
+			if gap.Dx() < pw {
+				continue
+			}
+
+			if bestShelf < 0 || gap.Dx() < p.shelves[bestShelf].free[bestGap].Dx() {
+				bestShelf, bestGap = i, j
+			}
+		}
+	}
+
+	if bestShelf < 0 {
+		return image.Rectangle{}, false
+	}
+
+	s := &p.shelves[bestShelf]
+	gap := s.free[bestGap]
+	s.free = append(s.free[:bestGap], s.free[bestGap+1:]...)
+
+	return gap, true
+}
+
+// The rect is not higher than the shelf, but also
+// not so small, that most of the shelf's height is wasted
+func fitsShelf(s shelf, ph int) bool {
+	return s.height >= ph && s.height-ph <= s.height/2
+}
+
+func (p *ShelfPacker) addShelf(height int) int {
+	height = (height + SHELF_GRANULARITY - 1) / SHELF_GRANULARITY * SHELF_GRANULARITY
+
+	// the padding at the top and left of the page
+	y := GLYPH_PADDING
+	if len(p.shelves) > 0 {
+		last := p.shelves[len(p.shelves)-1]
+		y = last.y + last.height
+	}
+
+	if y+height > p.height {
+		return -1
+	}
+
+	p.shelves = append(p.shelves, shelf{
+		y:      y,
+		height: height,
+		x:      GLYPH_PADDING,
+	})
+
+	return len(p.shelves) - 1
+}
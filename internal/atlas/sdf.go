@@ -0,0 +1,73 @@
+package atlas
+
+import (
+	"image"
+	"image/color"
+	"math"
+)
+
+// Size in pixels glyphs are rasterized at for their distance
+// fields, which are then scaled to whatever size is drawn
+const SDF_SIZE = 48
+
+// Distance in pixels of SDF_SIZE covered by a distance field
+// on either side of the outline, which limits how wide outlines
+// and glows can be
+const SDF_SPREAD = 8
+
+// Turns the coverage of a rasterized glyph into a signed distance
+// field, which is larger by spread on every side. Distances are
+// mapped to [0, 1], the outline is at 0.5 and the inside above it.
+// Like glyph bitmaps, the result carries its values in the red
+// channel, so it can be put into the atlas with Insert.
+func DistanceField(coverage *image.RGBA, spread int) *image.RGBA {
+	b := coverage.Rect
+	w := b.Dx() + 2*spread
+	h := b.Dy() + 2*spread
+
+	// coverage of the padded field, decided once per pixel
+	mask := make([]bool, w*h)
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			mask[(y-b.Min.Y+spread)*w+x-b.Min.X+spread] = coverage.RGBAAt(x, y).R >= 128
+		}
+	}
+
+	inside := func(x, y int) bool {
+		return x >= 0 && y >= 0 && x < w && y < h && mask[y*w+x]
+	}
+
+	field := image.NewRGBA(image.Rect(0, 0, w, h))
+
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			in := inside(x, y)
+
+			// nearest pixel on the other side of the outline,
+			// pixels beyond the spread end up at 0 or 1
+			nearest := (spread + 1) * (spread + 1)
+			for dy := -spread; dy <= spread; dy++ {
+				for dx := -spread; dx <= spread; dx++ {
+					d := dx*dx + dy*dy
+					if d < nearest && inside(x+dx, y+dy) != in {
+						nearest = d
+					}
+				}
+			}
+
+			// the outline runs between the two pixel centers
+			distance := math.Sqrt(float64(nearest)) - .5
+			if !in {
+				distance = -distance
+			}
+
+			v := .5 + distance/float64(2*spread)
+			v = math.Min(math.Max(v, 0), 1)
+
+			a := uint8(math.Round(v * 255))
+			field.SetRGBA(x, y, color.RGBA{a, a, a, 255})
+		}
+	}
+
+	return field
+}
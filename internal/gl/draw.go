@@ -0,0 +1,217 @@
+package gl
+
+import (
+	. "dyiui/internal/atlas"
+	. "dyiui/internal/color"
+	"dyiui/internal/render"
+	. "dyiui/internal/types"
+	"image"
+	"unsafe"
+
+	"github.com/go-gl/gl/v4.1-core/gl"
+)
+
+const GL_S_UINT = 4
+
+var VERTEX_SIZE = int32(unsafe.Sizeof(render.Vertex{}))
+
+// Creates the buffers, which are reused for the draw list
+// of every frame, and describes the vertex layout
+func makeDrawListBuffers() (VaoId, BufferId, BufferId) {
+	var vao VaoId
+	gl.GenVertexArrays(1, &vao)
+	gl.BindVertexArray(vao)
+
+	var vbo BufferId
+	gl.GenBuffers(1, &vbo)
+	gl.BindBuffer(gl.ARRAY_BUFFER, vbo)
+
+	var ebo BufferId
+	gl.GenBuffers(1, &ebo)
+	gl.BindBuffer(gl.ELEMENT_ARRAY_BUFFER, ebo)
+
+	gl.EnableVertexAttribArray(0)
+	gl.VertexAttribPointer(0, 2, gl.FLOAT, false, VERTEX_SIZE, nil)
+
+	gl.EnableVertexAttribArray(1)
+	gl.VertexAttribPointer(1, 2, gl.FLOAT, false, VERTEX_SIZE, gl.PtrOffset(GL_S_FLOAT*2))
+
+	gl.EnableVertexAttribArray(2)
+	gl.VertexAttribPointer(2, 4, gl.UNSIGNED_BYTE, true, VERTEX_SIZE, gl.PtrOffset(GL_S_FLOAT*4))
+
+	gl.BindVertexArray(0)
+
+	return vao, vbo, ebo
+}
+
+func (r *Renderer) UploadImage(img image.Image) render.TextureId {
+	r.images = append(r.images, NewImageTexture(img))
+
+	return render.TextureId{Kind: render.ImageTexture, Index: len(r.images) - 1}
+}
+
+func (r *Renderer) texture(id render.TextureId) *GlyphTexture {
+	if id.Kind == render.ImageTexture {
+		return r.images[id.Index]
+	}
+
+	return r.glyphTextures[id.Index]
+}
+
+// Mirrors the changed pages of the cpu side atlas into
+// their textures, creating textures for new pages
+func (r *Renderer) uploadAtlas(atlas *Atlas) {
+	for i, page := range atlas.Pages {
+		if i == len(r.glyphTextures) {
+			r.glyphTextures = append(r.glyphTextures, NewGlyphTexture(atlas.PageSize))
+		}
+
+		if page.Dirty {
+			UploadAtlasPage(r.glyphTextures[i], page)
+			page.Dirty = false
+		}
+	}
+}
+
+// Mirrors a page of the cpu side atlas into its texture
+func UploadAtlasPage(t *GlyphTexture, page *Page) {
+	gl.BindTexture(t.target, t.handle)
+	CheckGLErrorsPrint("BindTexture")
+
+	gl.TexSubImage2D(
+		t.target,
+		0,
+		0,
+		0,
+		int32(page.Image.Rect.Dx()),
+		int32(page.Image.Rect.Dy()),
+		gl.RGBA,
+		gl.UNSIGNED_BYTE,
+		gl.Ptr(page.Image.Pix),
+	)
+
+	gl.BindTexture(t.target, NULL_TEX_HANDLE)
+}
+
+// The scissor box is given in window coordinates,
+// which start at the bottom left
+func (r *Renderer) applyClip(clip Quad) {
+	if clip == render.NO_CLIP {
+		gl.Disable(gl.SCISSOR_TEST)
+		return
+	}
+
+	x0 := max(clip.X, 0)
+	y0 := max(clip.Y, 0)
+	x1 := min(clip.X+clip.W, Float(r.Width))
+	y1 := min(clip.Y+clip.H, Float(r.Height))
+
+	gl.Enable(gl.SCISSOR_TEST)
+	gl.Scissor(
+		int32(x0),
+		int32(Float(r.Height)-y1),
+		int32(max(x1-x0, 0)),
+		int32(max(y1-y0, 0)),
+	)
+}
+
+// Switches the shader between sampling textures and shading
+// distance fields, which are filtered linearly
+func (r *Renderer) applyTextEffect(sdf bool, effect render.TextEffect) {
+	shader := r.shaders.UIShader
+
+	if !sdf {
+		gl.Uniform1i(shader.Ul_Sdf, 0)
+		gl.BindSampler(0, 0)
+		return
+	}
+
+	outline := ColorToGlVec4(effect.OutlineColor)
+	glow := ColorToGlVec4(effect.GlowColor)
+
+	gl.Uniform1i(shader.Ul_Sdf, 1)
+	gl.Uniform1f(shader.Ul_Outline, effect.Outline)
+	gl.Uniform4fv(shader.Ul_OutlineColor, 1, &outline[0])
+	gl.Uniform1f(shader.Ul_Glow, effect.Glow)
+	gl.Uniform4fv(shader.Ul_GlowColor, 1, &glow[0])
+	gl.BindSampler(0, r.sdfSampler)
+}
+
+// Uploads data into a buffer, which is reused across frames.
+// The storage only grows, but is orphaned every frame, so the
+// driver does not need to wait for draws still reading from it.
+func streamBuffer(target uint32, capacity *int, size int, data unsafe.Pointer) {
+	if size > *capacity {
+		*capacity = max(size, *capacity*2)
+	}
+
+	gl.BufferData(target, *capacity, nil, gl.STREAM_DRAW)
+	gl.BufferSubData(target, 0, size, data)
+}
+
+type FrameStats struct {
+	DrawCalls int
+	Vertices  int
+	Indices   int
+}
+
+func (r *Renderer) FinishFrame(list *render.DrawList) {
+	r.Stats = FrameStats{
+		Vertices: len(list.Vertices),
+		Indices:  len(list.Indices),
+	}
+
+	r.uploadAtlas(r.GetAtlas())
+
+	if len(list.Indices) == 0 {
+		return
+	}
+
+	gl.UseProgram(r.shaders.UIShader.Program)
+	gl.Uniform2f(r.shaders.UIShader.Ul_Screen, Float(r.Width), Float(r.Height))
+
+	gl.BindVertexArray(r.vao)
+
+	gl.BindBuffer(gl.ARRAY_BUFFER, r.vbo)
+	streamBuffer(gl.ARRAY_BUFFER, &r.vboCapacity, len(list.Vertices)*int(VERTEX_SIZE), gl.Ptr(list.Vertices))
+
+	gl.BindBuffer(gl.ELEMENT_ARRAY_BUFFER, r.ebo)
+	streamBuffer(gl.ELEMENT_ARRAY_BUFFER, &r.eboCapacity, len(list.Indices)*GL_S_UINT, gl.Ptr(list.Indices))
+
+	gl.ActiveTexture(gl.TEXTURE0)
+
+	var bound *GlyphTexture
+	clip := render.NO_CLIP
+	sdf := false
+	effect := render.TextEffect{}
+	r.applyTextEffect(sdf, effect)
+
+	for _, batch := range list.Batches() {
+		if batch.Clip != clip {
+			r.applyClip(batch.Clip)
+			clip = batch.Clip
+		}
+
+		if tex := r.texture(batch.Texture); tex != bound {
+			gl.BindTexture(tex.target, tex.handle)
+			bound = tex
+		}
+
+		if batch.SDF != sdf || batch.Effect != effect {
+			r.applyTextEffect(batch.SDF, batch.Effect)
+			sdf, effect = batch.SDF, batch.Effect
+		}
+
+		gl.DrawElements(
+			gl.TRIANGLES,
+			int32(batch.IndexCount),
+			gl.UNSIGNED_INT,
+			gl.PtrOffset(batch.IndexOffset*GL_S_UINT),
+		)
+		r.Stats.DrawCalls += 1
+	}
+
+	gl.Disable(gl.SCISSOR_TEST)
+	gl.BindSampler(0, 0)
+	gl.BindVertexArray(0)
+}
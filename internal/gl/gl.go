@@ -1,6 +1,7 @@
 package gl
 
 import (
+	. "dyiui/internal/atlas"
 	. "dyiui/internal/types"
 	. "dyiui/internal/text"
 	"errors"
@@ -16,6 +17,9 @@ import (
 
 const GL_S_FLOAT = 4
 
+const WIN_WIDTH = 640
+const WIN_HEIGHT = 480
+
 // texture dump shaders
 const (
 	texDumpVSSource = `#version 410
@@ -119,8 +123,7 @@ type UniformInfo struct {
 }
 
 type shaders struct {
-    RectShader RectShader
-    TextShader TextShader
+    UIShader UIShader
 }
 
 type Renderer struct {
@@ -129,6 +132,22 @@ type Renderer struct {
     Height int
     Fonts FontRepo
     atlases AtlasRepo
+    // one per atlas page
+    glyphTextures []*GlyphTexture
+    images []*GlyphTexture
+    // filters distance fields linearly, the atlas
+    // itself is sampled with nearest filtering
+    sdfSampler uint32
+
+    // buffers the draw lists are streamed into
+    vao VaoId
+    vbo BufferId
+    ebo BufferId
+    vboCapacity int
+    eboCapacity int
+
+    // what the last frame cost
+    Stats FrameStats
 }
 
 func InitRenderer(initWidth, initHeight int) *Renderer {
@@ -166,12 +185,18 @@ func InitRenderer(initWidth, initHeight int) *Renderer {
         Fonts: NewFontRepo(),
 	}
 
-    path := GetSomeFont()
+    path, err := GetSomeFont()
+    if err != nil {
+        panic(err)
+    }
     fmt.Printf("loading font from %s\n", path)
-    r.Fonts.Load(path)
+    if _, err := r.Fonts.Load(path); err != nil {
+        panic(err)
+    }
 
-	r.shaders.TextShader = CreateTextShader()
-	r.shaders.RectShader = CreateRectShader()
+	r.shaders.UIShader = CreateUIShader()
+	r.vao, r.vbo, r.ebo = makeDrawListBuffers()
+	r.sdfSampler = newLinearSampler()
 
     gl.BlendFunc(gl.SRC_ALPHA, gl.ONE_MINUS_SRC_ALPHA)
     gl.Enable(gl.BLEND)
@@ -187,12 +212,7 @@ func (c *Renderer) ToClipSpaceY(v Float) Float {
 	return (2 * v / float32(c.Height)) - 1.0
 }
 
-func (c *Renderer) MapToClipSpace(q *Quad) {
-    q.X = c.ToClipSpaceX(q.X)
-    q.Y = c.ToClipSpaceY(q.Y)
-    q.W = c.ToClipSpaceX(q.W) + 1.0
-    q.H = c.ToClipSpaceY(q.H) + 1.0
-}
+const ATLAS_SIZE = 1024
 
 func (r *Renderer) GetAtlas() *Atlas {
     var id AtlasId = 0
@@ -200,13 +220,16 @@ func (r *Renderer) GetAtlas() *Atlas {
 
     if atlas == nil {
         fmt.Printf("atlas not existing yet, creating one\n")
-        tex := NewGlyphTexture(1024)
-        atlas = r.atlases.Add(id, *tex)
+        atlas = r.atlases.Add(id, ATLAS_SIZE)
     }
 
     return atlas
 }
 
+func (r *Renderer) GetFonts() *FontRepo {
+    return &r.Fonts
+}
+
 func FindUniformOrPanic(name string, infos []UniformInfo) UniformInfo {
     for _, i := range(infos) {
         if i.Name == name {
@@ -259,47 +282,13 @@ func GetUniformLocation(prog uint32, name string) int32 {
 	return loc
 }
 
-func BeginFrame() {
+func (r *Renderer) BeginFrame() {
+	r.Fonts.Layouts.NextFrame()
+
 	// Clear previous buffer
 	gl.Clear(gl.COLOR_BUFFER_BIT | gl.DEPTH_BUFFER_BIT)
 }
 
-func FinishFrame() {
-
-}
-
-func makeTextVertexArrays(vertices []float32) {
-    var buffer BufferId
-    gl.GenBuffers(1, &buffer)
-    gl.BindBuffer(gl.ARRAY_BUFFER, buffer)
-	gl.BufferData(gl.ARRAY_BUFFER, 4*len(vertices), gl.Ptr(vertices), gl.STATIC_DRAW)
-
-    var vao VaoId
-    gl.GenVertexArrays(1, &vao)
-    gl.BindVertexArray(vao)
-    gl.BindBuffer(gl.ARRAY_BUFFER, buffer)
-}
-
-func makeSegmentVaos(vertices []float32) (uint32, uint32) {
-	var buffer uint32
-	gl.GenBuffers(1, &buffer)
-	gl.BindBuffer(gl.ARRAY_BUFFER, buffer)
-	gl.BufferData(gl.ARRAY_BUFFER, 4*len(vertices), gl.Ptr(vertices), gl.STATIC_DRAW)
-
-	var g_pos uint32
-	gl.GenVertexArrays(1, &g_pos)
-	gl.BindVertexArray(g_pos)
-	gl.EnableVertexAttribArray(0)
-	gl.BindBuffer(gl.ARRAY_BUFFER, buffer)
-	gl.VertexAttribPointer(0, 2, gl.FLOAT, false, GL_S_FLOAT*4, nil)
-
-	gl.EnableVertexAttribArray(1)
-	gl.BindBuffer(gl.ARRAY_BUFFER, buffer)
-	gl.VertexAttribPointer(1, 2, gl.FLOAT, false, GL_S_FLOAT*4, gl.PtrOffset(GL_S_FLOAT*2))
-
-	return g_pos, g_pos
-}
-
 func compileShader(source string, shaderType uint32) (uint32, error) {
 	shader := gl.CreateShader(shaderType)
 
@@ -357,6 +346,59 @@ func NewGlyphTexture(size int32) *GlyphTexture {
 	return &texture
 }
 
+// Sampler, which overrides the filtering of the bound texture
+func newLinearSampler() uint32 {
+	var sampler uint32
+	gl.GenSamplers(1, &sampler)
+
+	gl.SamplerParameteri(sampler, gl.TEXTURE_WRAP_S, gl.CLAMP_TO_EDGE)
+	gl.SamplerParameteri(sampler, gl.TEXTURE_WRAP_T, gl.CLAMP_TO_EDGE)
+	gl.SamplerParameteri(sampler, gl.TEXTURE_MIN_FILTER, gl.LINEAR)
+	gl.SamplerParameteri(sampler, gl.TEXTURE_MAG_FILTER, gl.LINEAR)
+
+	return sampler
+}
+
+// Creates a texture of the image's size and uploads the image
+func NewImageTexture(img image.Image) *GlyphTexture {
+	r := img.Bounds()
+	nrgba := image.NewNRGBA(image.Rect(0, 0, r.Dx(), r.Dy()))
+	imageDraw.Draw(nrgba, nrgba.Rect, img, r.Min, imageDraw.Src)
+
+	var handle uint32
+	gl.GenTextures(1, &handle)
+
+	texture := GlyphTexture{
+		handle: handle,
+		target: GL_TEXTURE_2D,
+		width:  int32(r.Dx()),
+		height: int32(r.Dy()),
+	}
+
+	gl.BindTexture(GL_TEXTURE_2D, handle)
+
+	gl.TexParameteri(texture.target, gl.TEXTURE_WRAP_S, gl.CLAMP_TO_EDGE)
+	gl.TexParameteri(texture.target, gl.TEXTURE_WRAP_T, gl.CLAMP_TO_EDGE)
+	gl.TexParameteri(texture.target, gl.TEXTURE_MIN_FILTER, gl.LINEAR)
+	gl.TexParameteri(texture.target, gl.TEXTURE_MAG_FILTER, gl.LINEAR)
+
+	gl.TexImage2D(
+		GL_TEXTURE_2D,
+		0,
+		gl.SRGB8_ALPHA8,
+		texture.width,
+		texture.height,
+		0,
+		gl.RGBA,
+		gl.UNSIGNED_BYTE,
+		gl.Ptr(nrgba.Pix),
+	)
+
+	gl.BindTexture(GL_TEXTURE_2D, NULL_TEX_HANDLE)
+
+	return &texture
+}
+
 func ReadGlyphTexture(tex *GlyphTexture) image.Image {
 	gl.BindTexture(GL_TEXTURE_2D, tex.handle)
 	img := image.NewRGBA(image.Rect(0, 0, int(tex.width), int(tex.height)))
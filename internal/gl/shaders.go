@@ -1,69 +1,98 @@
 package gl
 
 const (
-	vertexShaderSource = `#version 410
+	uiVertexShaderSource = `#version 410
 
-	in vec2 a_pos;
-	in vec2 a_uv;
+	layout(location = 0) in vec2 a_pos;
+	layout(location = 1) in vec2 a_uv;
+	layout(location = 2) in vec4 a_color;
 
-    uniform vec2 offset;
+	// size of the window in pixels
+	uniform vec2 screen;
 
 	out vec2 uv;
-	out vec3 bc; // barycentric coordinates
+	out vec4 color;
 
-	const vec3 bcs[3] = vec3[3](
-		vec3(1.0, 0.0, 0.0),
-		vec3(0.0, 1.0, 0.0),
-		vec3(0.0, 0.0, 1.0)
-	);
-
-    void main() {
-		vec2 pos_normalized = vec2(a_pos.x, a_pos.y)* 2.0 + offset;
-        pos_normalized.y = -pos_normalized.y;
+	void main() {
+		vec2 pos_normalized = a_pos / screen * 2.0 - 1.0;
+		pos_normalized.y = -pos_normalized.y;
 
 		gl_Position = vec4(pos_normalized, .0, 1.0);
-		uv = vec2(a_uv.x, a_uv.y);
+		uv = a_uv;
 
-		// Add barycentric 
-		bc = bcs[gl_VertexID % 3];
-    }
+		// colors are packed as 0xRRGGBBAA, but read
+		// byte by byte from a little endian uint32
+		color = a_color.abgr;
+	}
 ` + "\x00"
 
-	fragmentShaderSource = `#version 410
+	uiFragmentShaderSource = `#version 410
 	in vec2 uv;
-	in vec3 bc; // barycentric coordinates
+	in vec4 color;
+
+	uniform sampler2D tex;
 
-	uniform sampler2D glyphTexture;
-    uniform float bWireframe;
-    uniform vec3 textColor;
+	// set for text drawn from distance fields,
+	// widths are in pixels on screen
+	uniform bool sdf;
+	uniform float outline;
+	uniform vec4 outline_color;
+	uniform float glow;
+	uniform vec4 glow_color;
 
-    out vec4 clr;
-    void main() {
+	out vec4 clr;
 
-		float b = 16.0 * bc[0] * bc[1] * bc[2];
-		if (b < .2) {
-			b = 1.0;
-		} else {
-			b = 0.0;
+	// composites colors with straight alpha
+	vec4 over(vec4 top, vec4 bottom) {
+		float a = top.a + bottom.a * (1.0 - top.a);
+		if (a == 0.0) {
+			return vec4(0.0);
 		}
-		vec4 wire_frame = vec4(1.0, .0, .0, 1.0) * b * bWireframe;
-        float opacity = vec4(texture(glyphTexture, uv)).r;
-        vec4 frag_clr = vec4(textColor, opacity);
+		return vec4((top.rgb * top.a + bottom.rgb * bottom.a * (1.0 - top.a)) / a, a);
+	}
 
-		clr = wire_frame + frag_clr;
-    }
+	void main() {
+		if (!sdf) {
+			clr = color * texture(tex, uv);
+			return;
+		}
+
+		float d = texture(tex, uv).a;
+		float g = length(vec2(dFdx(d), dFdy(d)));
+		// distance to the outline in pixels, positive inside
+		float px = (d - 0.5) / max(g, 1e-4);
+
+		vec4 c = vec4(color.rgb, color.a * clamp(px + 0.5, 0.0, 1.0));
+
+		if (outline > 0.0) {
+			float a = clamp(px + outline + 0.5, 0.0, 1.0);
+			c = over(c, vec4(outline_color.rgb, outline_color.a * a));
+		}
+
+		if (glow > 0.0) {
+			float fade = 1.0 - clamp(-(px + outline) / glow, 0.0, 1.0);
+			c = over(c, vec4(glow_color.rgb, glow_color.a * fade * fade));
+		}
+
+		clr = c;
+	}
 ` + "\x00"
 )
 
-type TextShader struct {
-    Program ProgramId
-	Ul_Offset UniformId
-    Ul_Wireframe UniformId
-    Ul_TextColor UniformId
+// Draws the vertices of a DrawList. Glyphs and plain rects
+// sample the white glyph atlas, images their own texture.
+type UIShader struct {
+	Program         ProgramId
+	Ul_Screen       UniformId
+	Ul_Sdf          UniformId
+	Ul_Outline      UniformId
+	Ul_OutlineColor UniformId
+	Ul_Glow         UniformId
+	Ul_GlowColor    UniformId
 }
 
-func CreateTextShader() TextShader {
-	r, err := CompileProgram(vertexShaderSource, fragmentShaderSource)
+func CreateUIShader() UIShader {
+	r, err := CompileProgram(uiVertexShaderSource, uiFragmentShaderSource)
 	if err != nil {
 		panic(err)
 	}
@@ -72,14 +101,13 @@ func CreateTextShader() TextShader {
 	//	DebugPrintUniformInfos(GetUniformInfos(r))
 	//}
 
-    uniforms := GetUniformInfos(r)
-
-    return TextShader{
-        Program: r,
-        Ul_Offset: FindUniformOrPanic("offset", uniforms).Index,
-        Ul_Wireframe: FindUniformOrPanic("bWireframe", uniforms).Index,
-        Ul_TextColor: FindUniformOrPanic("textColor", uniforms).Index,
-    }
+	return UIShader{
+		Program:         r,
+		Ul_Screen:       GetUniformLocation(r, "screen"),
+		Ul_Sdf:          GetUniformLocation(r, "sdf"),
+		Ul_Outline:      GetUniformLocation(r, "outline"),
+		Ul_OutlineColor: GetUniformLocation(r, "outline_color"),
+		Ul_Glow:         GetUniformLocation(r, "glow"),
+		Ul_GlowColor:    GetUniformLocation(r, "glow_color"),
+	}
 }
-
-
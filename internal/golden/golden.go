@@ -0,0 +1,190 @@
+/*
+Package golden renders widgets with the software backend
+and compares the result against checked in pngs.
+
+Run the tests with -update to (re)record the expected images.
+On a mismatch the rendered frame and a diff image are written
+into testdata/failures next to the golden images.
+*/
+package golden
+
+import (
+	. "dyiui/internal/layout"
+	"dyiui/internal/soft"
+	. "dyiui/internal/ui"
+	"flag"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "record the rendered images as new golden images")
+
+// Maximum difference per color channel,
+// which is still considered to be equal
+const DEFAULT_TOLERANCE = 2
+
+// Directory of the fonts checked in for tests
+func FontsDir() string {
+	_, file, _, _ := runtime.Caller(0)
+	return filepath.Join(filepath.Dir(file), "..", "..", "testdata", "fonts")
+}
+
+func FixtureFont() string {
+	return filepath.Join(FontsDir(), "Go-Regular.ttf")
+}
+
+// Covers a few glyphs the fixture font lacks, e.g. 中
+func FallbackFont() string {
+	return filepath.Join(FontsDir(), "cmapTest.ttf")
+}
+
+// Renders a single frame the way the app does, with the pointer
+// in the given state. Returns the renderer, so its target as well
+// as its atlas can be inspected.
+func Render(width, height int, pointer PointerState, fn func(ui *UI)) *soft.Renderer {
+	context := &Context{
+		Width:        uint32(width),
+		Height:       uint32(height),
+		PointerState: pointer,
+	}
+
+	return RenderContext(context, fn)
+}
+
+// Same as Render, but with a context, which can be kept
+// across frames to test widgets with state
+func RenderContext(context *Context, fn func(ui *UI)) *soft.Renderer {
+	renderer := soft.NewRenderer(int(context.Width), int(context.Height), FixtureFont())
+	if _, err := renderer.Fonts.Load(FallbackFont()); err != nil {
+		panic(err)
+	}
+	renderer.Fonts.Fallbacks = []string{"cmapTest"}
+
+	renderer.BeginFrame()
+	ui := NewUI(context, renderer)
+
+	ui.Begin(context.Width, context.Height)
+	fn(&ui)
+	ui.End()
+
+	renderer.FinishFrame(ui.DrawList)
+
+	return renderer
+}
+
+func Assert(t *testing.T, name string, img image.Image) {
+	t.Helper()
+	AssertWithin(t, name, img, DEFAULT_TOLERANCE)
+}
+
+// Compares img against testdata/<name>.png
+func AssertWithin(t *testing.T, name string, img image.Image, tolerance uint8) {
+	t.Helper()
+
+	path := filepath.Join("testdata", name+".png")
+
+	if *update {
+		if err := writePng(path, img); err != nil {
+			t.Fatalf("Could not record golden image %s: %v", path, err)
+		}
+		return
+	}
+
+	expected, err := loadPng(path)
+	if err != nil {
+		t.Fatalf("Could not load golden image %s, run with -update to record it: %v", path, err)
+	}
+
+	diff, mismatches := Compare(expected, img, tolerance)
+	if mismatches == 0 {
+		return
+	}
+
+	failures := filepath.Join("testdata", "failures")
+	actualPath := filepath.Join(failures, name+".png")
+	diffPath := filepath.Join(failures, name+".diff.png")
+
+	if err := os.MkdirAll(failures, 0o755); err == nil {
+		writePng(actualPath, img)
+		writePng(diffPath, diff)
+	}
+
+	t.Fatalf("%d pixels of %s differ by more than %d, see %s and %s", mismatches, name, tolerance, actualPath, diffPath)
+}
+
+// Counts the pixels, where a channel differs by more than the tolerance.
+// The diff image shows them in red on top of a faded copy of the expected image.
+func Compare(expected image.Image, actual image.Image, tolerance uint8) (*image.NRGBA, int) {
+	eb := expected.Bounds()
+	ab := actual.Bounds()
+
+	bounds := eb.Union(ab)
+	diff := image.NewNRGBA(bounds)
+	mismatches := 0
+
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			p := image.Pt(x, y)
+
+			if !p.In(eb) || !p.In(ab) {
+				diff.SetNRGBA(x, y, color.NRGBA{255, 0, 0, 255})
+				mismatches += 1
+				continue
+			}
+
+			e := color.NRGBAModel.Convert(expected.At(x, y)).(color.NRGBA)
+			a := color.NRGBAModel.Convert(actual.At(x, y)).(color.NRGBA)
+
+			if channelDiff(e.R, a.R) > tolerance ||
+				channelDiff(e.G, a.G) > tolerance ||
+				channelDiff(e.B, a.B) > tolerance ||
+				channelDiff(e.A, a.A) > tolerance {
+				diff.SetNRGBA(x, y, color.NRGBA{255, 0, 0, 255})
+				mismatches += 1
+				continue
+			}
+
+			gray := uint8((int(e.R) + int(e.G) + int(e.B)) / 3)
+			diff.SetNRGBA(x, y, color.NRGBA{gray, gray, gray, 64})
+		}
+	}
+
+	return diff, mismatches
+}
+
+func channelDiff(a, b uint8) uint8 {
+	if a > b {
+		return a - b
+	}
+	return b - a
+}
+
+func loadPng(path string) (image.Image, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return png.Decode(file)
+}
+
+func writePng(path string, img image.Image) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if err := png.Encode(file, img); err != nil {
+		return fmt.Errorf("encoding %s: %w", path, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,34 @@
+package golden
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestCompareRespectsTolerance(t *testing.T) {
+	a := image.NewNRGBA(image.Rect(0, 0, 4, 4))
+	b := image.NewNRGBA(image.Rect(0, 0, 4, 4))
+
+	b.SetNRGBA(1, 1, color.NRGBA{2, 0, 0, 0})
+	b.SetNRGBA(2, 2, color.NRGBA{0, 0, 0, 200})
+
+	_, mismatches := Compare(a, b, 2)
+	if mismatches != 1 {
+		t.Fatalf("Expected only the pixel beyond the tolerance to differ, got %d", mismatches)
+	}
+}
+
+func TestCompareMarksSizeDifference(t *testing.T) {
+	a := image.NewNRGBA(image.Rect(0, 0, 4, 4))
+	b := image.NewNRGBA(image.Rect(0, 0, 4, 5))
+
+	diff, mismatches := Compare(a, b, 0)
+	if mismatches != 4 {
+		t.Fatalf("Expected the additional row to differ, got %d", mismatches)
+	}
+
+	if diff.NRGBAAt(0, 4) != (color.NRGBA{255, 0, 0, 255}) {
+		t.Fatalf("Expected mismatches to be marked red")
+	}
+}
@@ -0,0 +1,34 @@
+package layout
+
+import (
+    . "dyiui/internal/render"
+    . "dyiui/internal/types"
+)
+
+// Restricts drawing and hit-testing of the following widgets to
+// the rect, within the clip rects pushed before. Has to be
+// matched by PopClipRect.
+func (ui *UI) PushClipRect(clip Quad) {
+    ui.DrawList.PushClip(clip)
+    ui.syncPointerClip()
+}
+
+// Restores the clip rect, which was active before the last PushClipRect
+func (ui *UI) PopClipRect() {
+    ui.DrawList.PopClip()
+    ui.syncPointerClip()
+}
+
+// The rect drawing is restricted to, NO_CLIP if unrestricted
+func (ui *UI) ClipRect() Quad {
+    return ui.DrawList.Clip()
+}
+
+func (ui *UI) syncPointerClip() {
+    clip := ui.DrawList.Clip()
+    if clip == NO_CLIP {
+        ui.Context.PointerState.Clip = nil
+    } else {
+        ui.Context.PointerState.Clip = &clip
+    }
+}
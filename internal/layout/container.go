@@ -0,0 +1,221 @@
+package layout
+
+import (
+    . "dyiui/internal/color"
+    . "dyiui/internal/types"
+    "fmt"
+)
+
+type Direction = int
+
+const (
+    // children are placed below each other
+    Column Direction = iota
+    // children are placed next to each other
+    Row
+    // children are placed on top of each other
+    Stack
+)
+
+type Style struct {
+    // gap between two children of a container
+    Spacing Float
+    // inner space between the border of a container and its children
+    Padding Float
+    TextColor Color
+    ScrollbarTrack Color
+    ScrollbarThumb Color
+    ScrollbarThumbHovered Color
+    Markdown MarkdownStyle
+}
+
+func DefaultStyle() Style {
+    return Style {
+        Spacing: 8.0,
+        Padding: 0.0,
+        TextColor: 0x4d4d4dff,
+        ScrollbarTrack: 0x1e1e1eff,
+        ScrollbarThumb: 0x5a5a5aff,
+        ScrollbarThumbHovered: 0x7a7a7aff,
+        Markdown: DefaultMarkdownStyle(),
+    }
+}
+
+type Container struct {
+    Id ElementId
+    Direction Direction
+
+    // top left corner of the container
+    X Float
+    Y Float
+
+    // space the container may occupy,
+    // measured from its top left corner
+    MaxWidth Float
+    MaxHeight Float
+
+    Spacing Float
+    Padding Float
+
+    // extent of the children placed so far,
+    // without the padding
+    Width Float
+    Height Float
+
+    Children int
+}
+
+// Returns the position, where the next child
+// of the container will be placed, without
+// advancing the cursor
+func (c *Container) next() (Float, Float) {
+    x := c.X + c.Padding
+    y := c.Y + c.Padding
+
+    switch c.Direction {
+    case Row:
+        x += c.Width
+        if c.Children > 0 {
+            x += c.Spacing
+        }
+    case Column:
+        y += c.Height
+        if c.Children > 0 {
+            y += c.Spacing
+        }
+    }
+
+    return x, y
+}
+
+// Reserves a box of the given size and advances the cursor
+func (c *Container) place(w, h Float) Quad {
+    x, y := c.next()
+
+    switch c.Direction {
+    case Row:
+        c.Width = x + w - (c.X + c.Padding)
+        c.Height = max(c.Height, h)
+    case Column:
+        c.Width = max(c.Width, w)
+        c.Height = y + h - (c.Y + c.Padding)
+    case Stack:
+        c.Width = max(c.Width, w)
+        c.Height = max(c.Height, h)
+    }
+
+    c.Children += 1
+
+    return NewQuad(x, y, w, h)
+}
+
+// Space left for the next child
+func (c *Container) available() (Float, Float) {
+    x, y := c.next()
+    w := c.MaxWidth - (x - c.X) - c.Padding
+    h := c.MaxHeight - (y - c.Y) - c.Padding
+
+    return max(w, 0), max(h, 0)
+}
+
+// Size of the container including its padding
+func (c *Container) outerSize() (Float, Float) {
+    return c.Width + 2.0 * c.Padding, c.Height + 2.0 * c.Padding
+}
+
+func (ui *UI) current() *Container {
+    if len(ui.containers) == 0 {
+        return nil
+    }
+
+    return &ui.containers[len(ui.containers)-1]
+}
+
+func (ui *UI) beginContainer(d Direction) {
+    // containers have no label, so they are identified
+    // by their position within the parent
+    index := 0
+    if parent := ui.current(); parent != nil {
+        index = parent.Children
+    }
+    id := ui.GetID(fmt.Sprintf("##container%d", index))
+
+    c := Container {
+        Id: id,
+        Direction: d,
+        Spacing: ui.Style.Spacing,
+        Padding: ui.Style.Padding,
+    }
+
+    if parent := ui.current(); parent != nil {
+        c.X, c.Y = parent.next()
+        c.MaxWidth, c.MaxHeight = parent.available()
+    }
+
+    ui.containers = append(ui.containers, c)
+    ui.pushParent(id, containerScope)
+}
+
+// Places following widgets next to each other,
+// until End is called
+func (ui *UI) BeginRow() {
+    ui.beginContainer(Row)
+}
+
+// Places following widgets below each other,
+// until End is called
+func (ui *UI) BeginColumn() {
+    ui.beginContainer(Column)
+}
+
+// Places following widgets on top of each other,
+// until End is called
+func (ui *UI) BeginStack() {
+    ui.beginContainer(Stack)
+}
+
+// Closes the most recently opened container
+// and reserves its size within the parent container
+func (ui *UI) End() {
+    c := ui.endContainer()
+    w, h := c.outerSize()
+
+    if parent := ui.current(); parent != nil {
+        parent.place(w, h)
+    }
+}
+
+// Closes the most recently opened container without placing it
+func (ui *UI) endContainer() Container {
+    c := ui.current()
+    if c == nil {
+        panic("End called without matching Begin")
+    }
+
+    closed := *c
+    ui.popParent(containerScope, "End called while an id pushed within the container is open")
+    ui.containers = ui.containers[:len(ui.containers)-1]
+
+    return closed
+}
+
+// Reserves a box of the given size within the current container.
+// Widgets call this, once they know how big they are.
+func (ui *UI) Place(w, h Float) Quad {
+    c := ui.current()
+    if c == nil {
+        return NewQuad(0, 0, w, h)
+    }
+
+    return c.place(w, h)
+}
+
+// Space left within the current container
+func (ui *UI) Available() (Float, Float) {
+    c := ui.current()
+    if c == nil {
+        return Float(ui.Context.Width), Float(ui.Context.Height)
+    }
+
+    return c.available()
+}
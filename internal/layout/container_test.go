@@ -0,0 +1,85 @@
+package layout
+
+import (
+	. "dyiui/internal/types"
+	. "dyiui/internal/ui"
+	"testing"
+)
+
+func testUI(spacing, padding Float) *UI {
+	ui := &UI{Context: &Context{Width: 200, Height: 100}}
+	ui.Style.Spacing = spacing
+	ui.Style.Padding = padding
+
+	ui.BeginColumn()
+	root := ui.current()
+	root.MaxWidth, root.MaxHeight = 200, 100
+
+	return ui
+}
+
+func TestColumnPlacesBelowWithSpacing(t *testing.T) {
+	ui := testUI(8, 4)
+
+	a := ui.Place(50, 10)
+	b := ui.Place(30, 20)
+
+	if a != NewQuad(4, 4, 50, 10) || b != NewQuad(4, 22, 30, 20) {
+		t.Fatalf("Expected the children below each other within the padding, got %v and %v", a, b)
+	}
+
+	if w, h := ui.Available(); w != 192 || h != 100-4-38-8-4 {
+		t.Fatalf("Expected the space below the last child, got %f×%f", w, h)
+	}
+}
+
+func TestRowPlacesNextToEachOther(t *testing.T) {
+	ui := testUI(8, 4)
+
+	ui.BeginRow()
+	a := ui.Place(50, 10)
+	b := ui.Place(30, 20)
+
+	if a != NewQuad(8, 8, 50, 10) || b != NewQuad(66, 8, 30, 20) {
+		t.Fatalf("Expected the children next to each other, got %v and %v", a, b)
+	}
+
+	if w, _ := ui.Available(); w != 192-4-88-8-4 {
+		t.Fatalf("Expected the space right of the last child, got %f", w)
+	}
+
+	ui.End()
+
+	// the row takes its outer size within the column
+	if next := ui.Place(10, 10); next != NewQuad(4, 4+28+8, 10, 10) {
+		t.Fatalf("Expected the row to be placed as one child, got %v", next)
+	}
+}
+
+func TestStackPlacesOnTopOfEachOther(t *testing.T) {
+	ui := testUI(8, 0)
+
+	ui.BeginStack()
+	a := ui.Place(50, 10)
+	b := ui.Place(30, 20)
+
+	if a != NewQuad(0, 0, 50, 10) || b != NewQuad(0, 0, 30, 20) {
+		t.Fatalf("Expected the children at the same position, got %v and %v", a, b)
+	}
+
+	c := *ui.current()
+	if w, h := c.outerSize(); w != 50 || h != 20 {
+		t.Fatalf("Expected the stack to be as big as its biggest children, got %f×%f", w, h)
+	}
+
+	ui.End()
+}
+
+func TestNothingIsAvailableBeyondTheContainer(t *testing.T) {
+	ui := testUI(0, 0)
+	ui.Place(150, 120)
+
+	if w, h := ui.Available(); w != 200 || h != 0 {
+		t.Fatalf("Expected no height to be left, got %f×%f", w, h)
+	}
+}
@@ -0,0 +1,5 @@
+//go:build debug
+
+package layout
+
+const DEBUG_IDS = true
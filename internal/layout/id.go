@@ -0,0 +1,115 @@
+package layout
+
+import (
+    . "dyiui/internal/types"
+    "encoding/binary"
+    "fmt"
+    "hash/fnv"
+    "strconv"
+    "strings"
+)
+
+// Separates the visible part of a label from the part,
+// which only serves to make the id unique. "Save##dialog"
+// is displayed as "Save", but does not collide with "Save".
+const ID_SEPARATOR = "##"
+
+// Hashes the label together with the id on top of the
+// parent stack. The same label results in the same id
+// across frames, as long as it is submitted within the same parents.
+func (ui *UI) GetID(label string) ElementId {
+    var seed ElementId
+    if len(ui.Parents) > 0 {
+        seed = ui.Parents[len(ui.Parents)-1]
+    }
+
+    return hashId(seed, label)
+}
+
+func hashId(seed ElementId, label string) ElementId {
+    var buf [4]byte
+    binary.LittleEndian.PutUint32(buf[:], seed)
+
+    h := fnv.New32a()
+    h.Write(buf[:])
+    h.Write([]byte(label))
+
+    return h.Sum32()
+}
+
+// Opens a new id scope, so widgets with the same labels
+// can be submitted multiple times, e.g. within a loop.
+// Needs to be closed with PopID.
+func (ui *UI) PushID(label string) {
+    ui.pushParent(ui.GetID(label), idScope)
+}
+
+func (ui *UI) PushIDInt(i int) {
+    ui.PushID(strconv.Itoa(i))
+}
+
+func (ui *UI) PopID() {
+    ui.popParent(idScope, "PopID called without matching PushID")
+}
+
+// What put an id onto the parent stack
+type scopeKind int
+
+const (
+    idScope scopeKind = iota
+    containerScope
+)
+
+func (ui *UI) pushParent(id ElementId, kind scopeKind) {
+    ui.Parents = append(ui.Parents, id)
+    ui.scopes = append(ui.scopes, kind)
+}
+
+// Scopes are closed in the reverse order, they were opened in,
+// so e.g. PopID does not close a container opened after PushID
+func (ui *UI) popParent(kind scopeKind, mismatch string) {
+    n := len(ui.scopes)
+    if n == 0 || ui.scopes[n-1] != kind {
+        panic(mismatch)
+    }
+
+    ui.scopes = ui.scopes[:n-1]
+    ui.Parents = ui.Parents[:len(ui.Parents)-1]
+}
+
+// Strips everything after ID_SEPARATOR
+func DisplayLabel(label string) string {
+    if i := strings.Index(label, ID_SEPARATOR); i >= 0 {
+        return label[:i]
+    }
+
+    return label
+}
+
+// Registers the id as submitted in this frame. In debug builds
+// ids, that are submitted twice, are reported, since such widgets
+// would share their interaction state.
+func (ui *UI) claimId(id ElementId, label string) {
+    if !DEBUG_IDS {
+        return
+    }
+
+    if prev, ok := ui.trackId(id, label); !ok {
+        fmt.Printf("duplicate id %d: '%s' collides with '%s', use PushID or a '##' suffix\n", id, label, prev)
+    }
+}
+
+// Remembers the id, unless it was submitted before in this
+// frame, then the label it was submitted with is returned
+func (ui *UI) trackId(id ElementId, label string) (string, bool) {
+    if ui.submitted == nil {
+        ui.submitted = make(map[ElementId]string)
+    }
+
+    if prev, ok := ui.submitted[id]; ok {
+        return prev, false
+    }
+
+    ui.submitted[id] = label
+    return "", true
+}
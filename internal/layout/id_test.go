@@ -0,0 +1,99 @@
+package layout
+
+import (
+	. "dyiui/internal/types"
+	. "dyiui/internal/ui"
+	"testing"
+)
+
+func expectPanic(t *testing.T, what string, fn func()) {
+	t.Helper()
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Expected %s to panic", what)
+		}
+	}()
+	fn()
+}
+
+func TestIdsAreDerivedFromLabels(t *testing.T) {
+	ui := UI{Context: &Context{}}
+
+	if ui.GetID("Save") != ui.GetID("Save") {
+		t.Fatalf("Expected the same label to result in the same id")
+	}
+
+	if ui.GetID("Save") == ui.GetID("Load") {
+		t.Fatalf("Expected different labels to result in different ids")
+	}
+
+	// the part after ## is only there to tell widgets apart
+	if ui.GetID("Save##dialog") == ui.GetID("Save") {
+		t.Fatalf("Expected the suffix to make the id unique")
+	}
+
+	for label, expected := range map[string]string{"Save##dialog": "Save", "##hidden": "", "Save": "Save"} {
+		if display := DisplayLabel(label); display != expected {
+			t.Errorf("Expected %q to be displayed as %q, got %q", label, expected, display)
+		}
+	}
+}
+
+func TestPushIDScopesIds(t *testing.T) {
+	ui := UI{Context: &Context{}}
+	outside := ui.GetID("Delete")
+
+	var ids []ElementId
+	for i := 0; i < 2; i++ {
+		ui.PushIDInt(i)
+		ids = append(ids, ui.GetID("Delete"))
+		ui.PopID()
+	}
+
+	if ids[0] == ids[1] || ids[0] == outside {
+		t.Fatalf("Expected every scope to have its own ids, got %v and %d", ids, outside)
+	}
+
+	if ui.GetID("Delete") != outside || len(ui.Parents) != 0 {
+		t.Fatalf("Expected PopID to restore the scope")
+	}
+}
+
+func TestMismatchedScopesPanic(t *testing.T) {
+	ui := UI{Context: &Context{}}
+
+	expectPanic(t, "PopID without PushID", ui.PopID)
+
+	ui.BeginRow()
+	expectPanic(t, "PopID closing a container", ui.PopID)
+	if len(ui.Parents) != 1 || len(ui.containers) != 1 {
+		t.Fatalf("Expected the row to stay open")
+	}
+
+	ui.PushID("open")
+	expectPanic(t, "End while an id is pushed", ui.End)
+	ui.PopID()
+	ui.End()
+
+	if len(ui.Parents) != 0 || len(ui.containers) != 0 {
+		t.Fatalf("Expected all scopes to be closed")
+	}
+}
+
+func TestDuplicateIdsAreDetected(t *testing.T) {
+	ui := UI{Context: &Context{}}
+
+	if _, ok := ui.trackId(ui.GetID("OK"), "OK"); !ok {
+		t.Fatalf("Expected the first id to be accepted")
+	}
+
+	if prev, ok := ui.trackId(ui.GetID("OK"), "OK"); ok || prev != "OK" {
+		t.Fatalf("Expected the second id to collide with the first")
+	}
+
+	ui.PushID("dialog")
+	if _, ok := ui.trackId(ui.GetID("OK"), "OK"); !ok {
+		t.Fatalf("Expected the id within another scope to be accepted")
+	}
+	ui.PopID()
+}
@@ -0,0 +1,360 @@
+package layout
+
+import (
+    . "dyiui/internal/color"
+    . "dyiui/internal/text"
+    . "dyiui/internal/types"
+    . "dyiui/internal/ui"
+    "math"
+    "unicode"
+    "unicode/utf8"
+)
+
+const INPUT_TEXT_WIDTH = 240.0
+const CARET_WIDTH = 2.0
+
+// Single line text field, which edits buf in place.
+// Returns true, if buf was changed during this frame.
+func (ui *UI) InputText(label string, buf *string) bool {
+    id := ui.GetID(label)
+    ui.claimId(id, label)
+    label = DisplayLabel(label)
+
+    paddingX := Float(8.0)
+    paddingY := Float(6.0)
+
+    fonts := ui.Renderer.GetFonts()
+    font := fonts.Get()
+    if font == nil {
+        // no font loaded yet, nothing to draw with
+        return false
+    }
+    lineHeight := font.Metrics.LineHeight()
+
+    // calculate layout
+    var labelPlacement RenderTextResult
+    labelWidth := Float(0)
+    if label != "" {
+        labelPlacement = fonts.Layouts.Place(label, font, math.MaxFloat32, TextLayoutOptions{})
+        labelWidth = labelPlacement.Width + ui.Style.Spacing
+    }
+
+    availableWidth, _ := ui.Available()
+    boxWidth := max(min(INPUT_TEXT_WIDTH, availableWidth - labelWidth), 2.0 * paddingX + CARET_WIDTH)
+    boxHeight := lineHeight + 2.0 * paddingY
+
+    placed := ui.Place(boxWidth + labelWidth, boxHeight)
+    box := NewQuad(placed.X, placed.Y, boxWidth, boxHeight)
+    inner := NewQuad(box.X + paddingX, box.Y + paddingY, boxWidth - 2.0 * paddingX, lineHeight)
+
+    // determine state
+    ctx := ui.Context
+    pointer := &ctx.PointerState
+    state := &ctx.TextInput
+    mouseOver := ctx.Hover(id, box)
+    ctx.ClickBehavior(id, mouseOver)
+
+    if pointer.JustActivated {
+        if mouseOver {
+            if ctx.FocusedId != id {
+                ctx.FocusedId = id
+                state.Reset(id)
+            }
+            state.Dragging = true
+        } else if ctx.FocusedId == id {
+            ctx.FocusedId = 0
+        }
+    }
+
+    focused := ctx.FocusedId == id
+    if focused && state.Id != id {
+        // focus was moved here from outside
+        state.Reset(id)
+    }
+
+    text := *buf
+    segment := ReorderLine(fonts.Layouts.Shape(font, text))
+    offsets, xs := caretPositions(text, segment)
+
+    if focused {
+        state.Clamp(text)
+
+        // the pointer first, so keys pressed during the same frame
+        // act on the caret it placed
+        if state.Dragging {
+            if pointer.Active {
+                pos := hitCaret(offsets, xs, pointer.PosX - inner.X + state.Scroll)
+                extend := !pointer.JustActivated || ctx.Keyboard.HasModifiers(ModShift)
+                state.MoveTo(pos, extend)
+            } else {
+                state.Dragging = false
+            }
+        }
+
+        edited := ui.editText(state, text)
+        if edited != text {
+            text = edited
+            segment = ReorderLine(fonts.Layouts.Shape(font, text))
+            offsets, xs = caretPositions(text, segment)
+        }
+    }
+
+    // keep the caret visible
+    scroll := Float(0)
+    if focused {
+        x := caretX(offsets, xs, state.Caret)
+        if x - state.Scroll > inner.W - CARET_WIDTH {
+            state.Scroll = x - inner.W + CARET_WIDTH
+        }
+        if x < state.Scroll {
+            state.Scroll = x
+        }
+        state.Scroll = min(state.Scroll, max(segment.Width + CARET_WIDTH - inner.W, 0))
+        state.Scroll = max(state.Scroll, 0)
+        scroll = state.Scroll
+    }
+
+    // render elements
+    var background Color = 0xddddddff
+    if focused {
+        background = 0xffffffff
+    } else if mouseOver {
+        background = 0xeeeeeeff
+    }
+    ui.DrawList.AddRect(box, background)
+
+    ui.PushClipRect(inner)
+
+    if focused && state.HasSelection() {
+        // runs of right to left text split the selection up
+        start, end := state.Selection()
+        for _, b := range selectedRunes(text, segment, start, end) {
+            ui.DrawList.AddRect(NewQuad(inner.X + b.x - scroll, inner.Y, b.w, lineHeight), 0x3399ff80)
+        }
+    }
+
+    placement := RenderTextResult {
+        Width: segment.Width,
+        Height: lineHeight,
+        Indices: len(segment.Glyphs),
+        PlacedSegments: []PlacedSegment{ {
+            Segment: segment,
+            Baseline: font.Metrics.Baseline(lineHeight),
+        } },
+    }
+    ui.DrawList.AddText(placement, NewQuad(inner.X - scroll, inner.Y, 0, 0), ui.Style.TextColor)
+
+    if focused {
+        x := caretX(offsets, xs, state.Caret)
+        ui.DrawList.AddRect(NewQuad(inner.X + x - scroll, inner.Y, CARET_WIDTH, lineHeight), ui.Style.TextColor)
+    }
+
+    ui.PopClipRect()
+
+    if label != "" {
+        labelPos := NewQuad(box.X + box.W + ui.Style.Spacing, inner.Y, 0, 0)
+        ui.DrawList.AddText(labelPlacement, labelPos, ui.Style.TextColor)
+    }
+
+    // tell state
+    changed := text != *buf
+    *buf = text
+    return changed
+}
+
+// Applies the keyboard input of this frame to the text
+func (ui *UI) editText(state *TextInputState, text string) string {
+    kb := &ui.Context.Keyboard
+    shift := kb.HasModifiers(ModShift)
+    word := kb.HasModifiers(ModControl)
+    pressed := kb.IsPressedOrRepeated
+
+    if pressed(KeyLeft) {
+        if state.HasSelection() && !shift {
+            start, _ := state.Selection()
+            state.MoveTo(start, false)
+        } else if word {
+            state.MoveTo(PrevWord(text, state.Caret), shift)
+        } else {
+            state.MoveTo(PrevRune(text, state.Caret), shift)
+        }
+    }
+
+    if pressed(KeyRight) {
+        if state.HasSelection() && !shift {
+            _, end := state.Selection()
+            state.MoveTo(end, false)
+        } else if word {
+            state.MoveTo(NextWord(text, state.Caret), shift)
+        } else {
+            state.MoveTo(NextRune(text, state.Caret), shift)
+        }
+    }
+
+    if pressed(KeyHome) {
+        state.MoveTo(0, shift)
+    }
+
+    if pressed(KeyEnd) {
+        state.MoveTo(len(text), shift)
+    }
+
+    if pressed(KeyBackspace) {
+        if word {
+            text = state.DeleteTo(text, PrevWord(text, state.Caret))
+        } else {
+            text = state.DeleteTo(text, PrevRune(text, state.Caret))
+        }
+    }
+
+    if pressed(KeyDelete) {
+        if word {
+            text = state.DeleteTo(text, NextWord(text, state.Caret))
+        } else {
+            text = state.DeleteTo(text, NextRune(text, state.Caret))
+        }
+    }
+
+    if word && kb.IsPressed(KeyA) {
+        state.SelectAll(text)
+    }
+
+    if kb.IsPressed(KeyEnter) || kb.IsPressed(KeyEscape) {
+        ui.Context.FocusedId = 0
+    }
+
+    // shortcuts produce no runes, but be safe. Ctrl together
+    // with Alt is AltGr, which types characters on some layouts
+    if !word || kb.HasModifiers(ModAlt) {
+        for _, r := range kb.Runes {
+            if unicode.IsPrint(r) {
+                text = state.Insert(text, string(r))
+            }
+        }
+    }
+
+    return text
+}
+
+// Where a rune is drawn, its glyphs are next to each other
+type runeBox struct {
+    x Float
+    w Float
+    rtl bool
+}
+
+// The caret in front of the rune in reading direction
+func (b runeBox) leading() Float {
+    if b.rtl {
+        return b.x + b.w
+    }
+    return b.x
+}
+
+func (b runeBox) trailing() Float {
+    if b.rtl {
+        return b.x
+    }
+    return b.x + b.w
+}
+
+// Boxes of the runes of the text, which is drawn as the segment in
+// visual order. Advances of glyphs are attributed to the rune they
+// were shaped from, runes merged into the cluster of the one before
+// get an empty box at its end.
+func runeBoxes(text string, visual Segment) []runeBox {
+    runes := utf8.RuneCountInString(text)
+    boxes := make([]runeBox, runes)
+    seen := make([]bool, runes)
+
+    x := Float(0)
+    for _, g := range visual.Glyphs {
+        if c := g.Cluster; c < runes {
+            if seen[c] {
+                boxes[c].w += g.XAdvance
+            } else {
+                boxes[c] = runeBox { x, g.XAdvance, g.Level % 2 == 1 }
+                seen[c] = true
+            }
+        }
+        x += g.XAdvance
+    }
+
+    for i := 1; i < runes; i++ {
+        if !seen[i] {
+            prev := boxes[i - 1]
+            boxes[i] = runeBox { prev.trailing(), 0, prev.rtl }
+        }
+    }
+
+    return boxes
+}
+
+// Byte offsets, at which the caret can be placed, and their
+// distance from the start of the drawn text in pixels. Within
+// right to left runs, the caret moves to the left.
+func caretPositions(text string, visual Segment) ([]int, []Float) {
+    boxes := runeBoxes(text, visual)
+
+    offsets := make([]int, 0, len(boxes) + 1)
+    xs := make([]Float, 0, len(boxes) + 1)
+
+    i := 0
+    for offset := range text {
+        offsets = append(offsets, offset)
+        xs = append(xs, boxes[i].leading())
+        i += 1
+    }
+
+    end := Float(0)
+    if len(boxes) > 0 {
+        end = boxes[len(boxes) - 1].trailing()
+    }
+
+    offsets = append(offsets, len(text))
+    xs = append(xs, end)
+
+    return offsets, xs
+}
+
+// Boxes of the runes between the byte offsets
+func selectedRunes(text string, visual Segment, start, end int) []runeBox {
+    boxes := runeBoxes(text, visual)
+
+    var selected []runeBox
+    i := 0
+    for offset := range text {
+        if offset >= start && offset < end {
+            selected = append(selected, boxes[i])
+        }
+        i += 1
+    }
+
+    return selected
+}
+
+func caretX(offsets []int, xs []Float, pos int) Float {
+    for i, o := range offsets {
+        if o >= pos {
+            return xs[i]
+        }
+    }
+
+    return xs[len(xs)-1]
+}
+
+// Caret position closest to x
+func hitCaret(offsets []int, xs []Float, x Float) int {
+    best := 0
+    bestDistance := Float(math.MaxFloat32)
+
+    for i, cx := range xs {
+        d := Float(math.Abs(float64(cx - x)))
+        if d < bestDistance {
+            best = offsets[i]
+            bestDistance = d
+        }
+    }
+
+    return best
+}
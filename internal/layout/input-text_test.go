@@ -0,0 +1,61 @@
+package layout
+
+import (
+	. "dyiui/internal/text"
+	. "dyiui/internal/types"
+	. "dyiui/internal/ui"
+	"testing"
+)
+
+func TestCaretsFollowTheVisualOrder(t *testing.T) {
+	// "ab" followed by two hebrew letters, drawn right to left
+	text := "abאב"
+	visual := Segment{Glyphs: []Glyph{
+		{XAdvance: 10, Cluster: 0},
+		{XAdvance: 10, Cluster: 1},
+		{XAdvance: 10, Cluster: 3, Level: 1},
+		{XAdvance: 10, Cluster: 2, Level: 1},
+	}}
+
+	offsets, xs := caretPositions(text, visual)
+
+	expected := []Float{0, 10, 40, 30, 20}
+	if len(offsets) != len(expected) {
+		t.Fatalf("Expected %d caret positions, got %d", len(expected), len(offsets))
+	}
+	for i, x := range expected {
+		if xs[i] != x {
+			t.Errorf("Expected the caret at byte %d to be at %v, got %v", offsets[i], x, xs[i])
+		}
+	}
+
+	// selecting the first hebrew letter highlights the right end
+	selected := selectedRunes(text, visual, 2, 4)
+	if len(selected) != 1 || selected[0].x != 30 || selected[0].w != 10 {
+		t.Errorf("Expected the selection to cover 30 to 40, got %v", selected)
+	}
+}
+
+func TestAltGrTypesCharacters(t *testing.T) {
+	ui := UI{Context: &Context{}}
+	kb := &ui.Context.Keyboard
+	state := &TextInputState{Caret: 1, Anchor: 1}
+
+	// AltGr arrives as Ctrl and Alt on some platforms
+	kb.QueueKey(KeyLeftControl, KeyPress, ModControl)
+	kb.QueueKey(KeyLeftAlt, KeyPress, ModControl|ModAlt)
+	kb.QueueRune('@')
+	kb.NewFrame()
+
+	if text := ui.editText(state, "a"); text != "a@" {
+		t.Errorf("Expected AltGr to type a character, got %q", text)
+	}
+
+	kb.QueueKey(KeyLeftAlt, KeyRelease, ModControl)
+	kb.QueueRune('x')
+	kb.NewFrame()
+
+	if text := ui.editText(state, "a@"); text != "a@" {
+		t.Errorf("Expected no characters to be typed with Ctrl held, got %q", text)
+	}
+}
@@ -0,0 +1,254 @@
+package layout
+
+import (
+    . "dyiui/internal/color"
+    "dyiui/internal/markdown"
+    . "dyiui/internal/text"
+    . "dyiui/internal/types"
+    . "dyiui/internal/ui"
+    "math"
+    "strconv"
+)
+
+// How Markdown sets the blocks
+type MarkdownStyle struct {
+    // size of headings of level 1 to 6 relative to the default font
+    HeadingScale [6]Float
+    // of inline code and code blocks, the default
+    // family is used, if it is not loaded
+    CodeFamily string
+    CodeColor Color
+    CodeBackground Color
+    // space around code blocks
+    CodePadding Float
+    LinkColor Color
+    LinkHoveredColor Color
+    QuoteColor Color
+    // width of the bar left of block quotes
+    QuoteBar Float
+    // per level of list items and block quotes
+    Indent Float
+}
+
+func DefaultMarkdownStyle() MarkdownStyle {
+    return MarkdownStyle {
+        HeadingScale: [6]Float{ 1.6, 1.35, 1.15, 1.0, 1.0, 1.0 },
+        CodeFamily: "Go Mono",
+        CodeColor: 0xb0b0b0ff,
+        CodeBackground: 0x2a2a2aff,
+        CodePadding: 6.0,
+        LinkColor: 0x3b78d8ff,
+        LinkHoveredColor: 0x6aa0f0ff,
+        QuoteColor: 0x808080ff,
+        QuoteBar: 3.0,
+        Indent: 36.0,
+    }
+}
+
+// Draws the markdown source block by block, see package markdown
+// for what is supported. Returns the target of the link clicked
+// during this frame, whose id is reported in ui.Clicked as well.
+// Links are identified by their target within the scope of their
+// block, which is pushed with the index of the block.
+func (ui *UI) Markdown(src string) string {
+    fonts := ui.Renderer.GetFonts()
+    font := fonts.Get()
+    if font == nil {
+        // no font loaded yet, nothing to draw with
+        return ""
+    }
+
+    clicked := ""
+    for i, block := range ui.markdownBlocks(src) {
+        ui.PushIDInt(i)
+        if href := ui.markdownBlock(fonts, font, block); href != "" {
+            clicked = href
+        }
+        ui.PopID()
+    }
+
+    return clicked
+}
+
+// Parsed once per source, as long as it is drawn every frame
+type markdownDocument struct {
+    src string
+    blocks []markdown.Block
+}
+
+func (ui *UI) markdownBlocks(src string) []markdown.Block {
+    doc := GetState[markdownDocument](ui.Context, ui.GetID("##markdown" + src))
+    if doc.blocks == nil || doc.src != src {
+        doc.src = src
+        doc.blocks = markdown.Parse(src)
+    }
+
+    return doc.blocks
+}
+
+func (ui *UI) markdownBlock(fonts *FontRepo, font *FontRepoEntry, b markdown.Block) string {
+    style := ui.Style.Markdown
+
+    indent := Float(b.Quote) * style.Indent
+    if b.Kind == markdown.ListItem {
+        indent += Float(b.Level + 1) * style.Indent
+    }
+
+    width, _ := ui.Available()
+    textWidth := max(width - indent, 1.0)
+
+    if b.Kind == markdown.CodeBlock {
+        code := fonts.Find(FontQuery {
+            FontDescriptor: FontDescriptor { Family: style.CodeFamily, Weight: WeightRegular },
+            Size: font.Size,
+        })
+        pad := style.CodePadding
+
+        // code is not wrapped, long lines are cut off by the clip
+        placement := fonts.Layouts.Place(b.Code, code, math.MaxFloat32, TextLayoutOptions{})
+
+        box := ui.Place(width, placement.Height + 2.0 * pad)
+        ui.quoteBars(box, b.Quote)
+        ui.DrawList.AddRect(NewQuad(box.X + indent, box.Y, box.W - indent, box.H), style.CodeBackground)
+        ui.PushClipRect(NewQuad(box.X + indent, box.Y, box.W - indent, box.H))
+        ui.DrawList.AddText(placement, NewQuad(box.X + indent + pad, box.Y + pad, 0, 0), style.CodeColor)
+        ui.PopClipRect()
+
+        return ""
+    }
+
+    base := font
+    if b.Kind == markdown.Heading {
+        base = font.WithSize(font.Size * style.HeadingScale[b.Level - 1])
+    }
+
+    rich, links := ui.markdownSpans(fonts, base, b)
+    placement := fonts.Layouts.PlaceRich(rich, base, textWidth, TextLayoutOptions{})
+
+    box := ui.Place(width, placement.Height)
+    pos := NewQuad(box.X + indent, box.Y, 0, 0)
+    ui.quoteBars(box, b.Quote)
+
+    if b.Kind == markdown.ListItem {
+        ui.listMarker(fonts, font, b, pos, placement)
+    }
+
+    // links, of which any part is hovered, are highlighted as a whole
+    hovered := map[string]bool{}
+    EachSpanRun(placement, func(r SpanRun) {
+        href := links[r.Span]
+        if href == "" {
+            return
+        }
+
+        bounds := r.Bounds()
+        rect := NewQuad(pos.X + bounds.X, pos.Y + bounds.Y, bounds.W, bounds.H)
+        if ui.Context.Hover(ui.GetID(href), rect) {
+            hovered[href] = true
+        }
+    })
+
+    clicked := ""
+    highlighted := map[string]bool{}
+    for _, href := range links {
+        if href == "" || highlighted[href] {
+            continue
+        }
+
+        id := ui.GetID(href)
+        ui.claimId(id, href)
+        held, linkClicked := ui.Context.ClickBehavior(id, hovered[href])
+        if linkClicked {
+            clicked = href
+            ui.Clicked = id
+        }
+
+        highlighted[href] = hovered[href] || held
+    }
+
+    for i := range rich {
+        if highlighted[links[i]] {
+            rich[i].Color = style.LinkHoveredColor
+        }
+    }
+
+    ui.DrawList.AddRichText(placement, rich, pos)
+
+    return clicked
+}
+
+// Spans of the inlines of the block along with the targets of links
+func (ui *UI) markdownSpans(fonts *FontRepo, base *FontRepoEntry, b markdown.Block) (RichText, []string) {
+    style := ui.Style.Markdown
+
+    color := ui.Style.TextColor
+    if b.Quote > 0 {
+        color = style.QuoteColor
+    }
+
+    rich := make(RichText, 0, len(b.Inlines))
+    links := make([]string, 0, len(b.Inlines))
+
+    for _, in := range b.Inlines {
+        d := base.Source.Descriptor
+        if b.Kind == markdown.Heading || in.Style & markdown.Strong != 0 {
+            d.Weight = WeightBold
+        }
+        if in.Style & markdown.Emphasis != 0 {
+            d.Italic = true
+        }
+        if in.Style & markdown.Code != 0 {
+            d.Family = style.CodeFamily
+        }
+
+        span := Span {
+            Text: in.Text,
+            Font: fonts.Find(FontQuery { FontDescriptor: d, Size: base.Size }),
+            Color: color,
+        }
+
+        if in.Style & markdown.Code != 0 {
+            span.Color = style.CodeColor
+            span.Background = style.CodeBackground
+        }
+
+        if in.Style & markdown.Link != 0 {
+            span.Color = style.LinkColor
+            span.Underline = true
+        }
+
+        rich = append(rich, span)
+        links = append(links, in.Href)
+    }
+
+    return rich, links
+}
+
+// Draws the bullet or number in front of the first line of the item
+func (ui *UI) listMarker(fonts *FontRepo, font *FontRepoEntry, b markdown.Block, pos Quad, item RenderTextResult) {
+    marker := "•"
+    if b.Ordered {
+        marker = strconv.Itoa(b.Number) + "."
+    }
+
+    placement := fonts.Layouts.Place(marker, font, math.MaxFloat32, TextLayoutOptions{})
+
+    // on the baseline of the first line
+    y := pos.Y
+    if len(item.PlacedSegments) > 0 && len(placement.PlacedSegments) > 0 {
+        y += item.PlacedSegments[0].Baseline - placement.PlacedSegments[0].Baseline
+    }
+
+    x := pos.X - placement.Width - ui.Style.Markdown.Indent / 4.0
+    ui.DrawList.AddText(placement, NewQuad(x, y, 0, 0), ui.Style.TextColor)
+}
+
+// Draws a bar for every block quote, the box is within
+func (ui *UI) quoteBars(box Quad, quotes int) {
+    style := ui.Style.Markdown
+
+    for q := 0; q < quotes; q++ {
+        x := box.X + Float(q) * style.Indent
+        ui.DrawList.AddRect(NewQuad(x, box.Y, style.QuoteBar, box.H), style.QuoteColor)
+    }
+}
@@ -0,0 +1,161 @@
+package layout
+
+import (
+    . "dyiui/internal/types"
+    . "dyiui/internal/ui"
+    "math"
+)
+
+// Pixels scrolled per step of the mouse wheel
+const SCROLL_STEP = 40.0
+const SCROLLBAR_WIDTH = 10.0
+// Thumbs of long content do not get shorter than this
+const SCROLLBAR_MIN_THUMB = 20.0
+
+type scrollArea struct {
+    id ElementId
+    view Quad
+    state *ScrollState
+}
+
+// Opens a viewport of the given size, the following widgets are
+// placed in a column scrolled by the wheel or the scrollbars.
+// The content is measured, while it is placed, so the scrollbars
+// and the offset take effect from the frame after it changed.
+// Has to be matched by EndScrollArea.
+func (ui *UI) BeginScrollArea(label string, w, h Float) {
+    id := ui.GetID(label)
+    ui.claimId(id, label)
+
+    view := ui.Place(w, h)
+    state := GetState[ScrollState](ui.Context, id)
+    state.Clamp(w, h)
+
+    ui.scrollAreas = append(ui.scrollAreas, scrollArea { id, view, state })
+    ui.PushClipRect(view)
+
+    // room for the scrollbars of content, which did not fit before
+    contentWidth := w
+    if state.ContentHeight > h {
+        contentWidth -= SCROLLBAR_WIDTH
+    }
+
+    ui.beginContainer(Column)
+    c := ui.current()
+    c.X = view.X - state.OffsetX
+    c.Y = view.Y - state.OffsetY
+    c.MaxWidth = contentWidth
+    c.MaxHeight = math.MaxFloat32
+}
+
+// Closes the scroll area, scrolls it and draws its scrollbars.
+// Returns true, if it was scrolled during this frame.
+func (ui *UI) EndScrollArea() bool {
+    if len(ui.scrollAreas) == 0 {
+        panic("EndScrollArea called without matching BeginScrollArea")
+    }
+
+    area := ui.scrollAreas[len(ui.scrollAreas) - 1]
+    ui.scrollAreas = ui.scrollAreas[:len(ui.scrollAreas) - 1]
+
+    c := ui.endContainer()
+    ui.PopClipRect()
+
+    view := area.view
+    state := area.state
+    state.ContentWidth, state.ContentHeight = c.outerSize()
+
+    x, y := state.OffsetX, state.OffsetY
+
+    // inner areas end first and take the wheel,
+    // so the areas around them stay where they are
+    pointer := &ui.Context.PointerState
+    if pointer.IsWithin(view.X, view.Y, view.W, view.H) {
+        state.OffsetX -= pointer.WheelX * SCROLL_STEP
+        state.OffsetY -= pointer.WheelY * SCROLL_STEP
+        pointer.WheelX, pointer.WheelY = 0, 0
+    }
+
+    overflowsX := state.ContentWidth > view.W
+    overflowsY := state.ContentHeight > view.H
+
+    if overflowsY {
+        track := NewQuad(view.X + view.W - SCROLLBAR_WIDTH, view.Y, SCROLLBAR_WIDTH, view.H)
+        if overflowsX {
+            track.H -= SCROLLBAR_WIDTH
+        }
+        ui.scrollbar(hashId(area.id, "##scrollbar-v"), state, Vertical, track, view.H)
+    }
+
+    if overflowsX {
+        track := NewQuad(view.X, view.Y + view.H - SCROLLBAR_WIDTH, view.W, SCROLLBAR_WIDTH)
+        if overflowsY {
+            track.W -= SCROLLBAR_WIDTH
+        }
+        ui.scrollbar(hashId(area.id, "##scrollbar-h"), state, Horizontal, track, view.W)
+    }
+
+    if !pointer.Active {
+        state.Dragging = NoAxis
+    }
+
+    state.Clamp(view.W, view.H)
+
+    return state.OffsetX != x || state.OffsetY != y
+}
+
+// Draws the scrollbar of an axis. Its thumb can be dragged,
+// clicking the track beside it scrolls by a page.
+func (ui *UI) scrollbar(id ElementId, state *ScrollState, axis ScrollAxis, track Quad, view Float) {
+    // everything along the axis
+    start, length := track.Y, track.H
+    content, offset := state.ContentHeight, &state.OffsetY
+    pos := ui.Context.PointerState.PosY
+    if axis == Horizontal {
+        start, length = track.X, track.W
+        content, offset = state.ContentWidth, &state.OffsetX
+        pos = ui.Context.PointerState.PosX
+    }
+
+    thumbLength := min(max(length * view / content, SCROLLBAR_MIN_THUMB), length)
+    scrollable := content - view
+    travel := length - thumbLength
+
+    thumbStart := start
+    if scrollable > 0 {
+        thumbStart += min(max(*offset / scrollable, 0), 1) * travel
+    }
+
+    thumb := NewQuad(track.X, thumbStart, track.W, thumbLength)
+    if axis == Horizontal {
+        thumb = NewQuad(thumbStart, track.Y, thumbLength, track.H)
+    }
+
+    pointer := &ui.Context.PointerState
+    hovered := ui.Context.Hover(id, track)
+    held, _ := ui.Context.ClickBehavior(id, hovered)
+    overThumb := hovered && pointer.IsWithin(thumb.X, thumb.Y, thumb.W, thumb.H)
+
+    if held && pointer.JustActivated {
+        if overThumb {
+            state.Dragging = axis
+            state.Grab = pos - thumbStart
+        } else if pos < thumbStart {
+            *offset -= view
+        } else {
+            *offset += view
+        }
+    }
+
+    if state.Dragging == axis && held && travel > 0 {
+        *offset = (pos - state.Grab - start) / travel * scrollable
+    }
+
+    color := ui.Style.ScrollbarThumb
+    if overThumb || state.Dragging == axis {
+        color = ui.Style.ScrollbarThumbHovered
+    }
+
+    ui.DrawList.AddRect(track, ui.Style.ScrollbarTrack)
+    ui.DrawList.AddRect(thumb, color)
+}
@@ -2,42 +2,58 @@ package layout
 
 import (
 	. "dyiui/internal/color"
-	. "dyiui/internal/gl"
+	. "dyiui/internal/render"
+	. "dyiui/internal/text"
 	. "dyiui/internal/types"
 	. "dyiui/internal/ui"
 	"fmt"
 )
 
-type ElementId = uint32
-
 type UI struct {
     Parents []ElementId
     Clicked ElementId
     Context *Context
-    Renderer *Renderer
+    Renderer Renderer
+    DrawList *DrawList
+    Style Style
+
+    containers []Container
+    // of the entries of Parents
+    scopes []scopeKind
+    scrollAreas []scrollArea
+    // ids submitted in this frame, only tracked with DEBUG_IDS
+    submitted map[ElementId]string
 }
 
-func NewUI(context *Context, r *Renderer) UI {
+// Called once per frame, which is when the context
+// begins a new frame of interaction
+func NewUI(context *Context, r Renderer) UI {
+    context.NewFrame()
+
     return UI {
         Context: context,
         Renderer: r,
+        DrawList: NewDrawList(r.GetAtlas()),
+        Style: DefaultStyle(),
     }
 }
 
-func Begin(x, y uint32) {
-}
-
-func (ui *UI) GenerateNewId() ElementId {
-    if ui.Parents == nil || len(ui.Parents) == 0 {
-        return 1;
-    }
+// Opens the root container spanning the given area.
+// Widgets are placed below each other by default.
+func (ui *UI) Begin(width, height uint32) {
+    // nothing is clipped at the start of a frame
+    ui.syncPointerClip()
+    ui.BeginColumn()
 
-    parentId := ui.Parents[len(ui.Parents)-1];
-    return parentId *  139123 % 45837;
+    root := ui.current()
+    root.MaxWidth = Float(width)
+    root.MaxHeight = Float(height)
 }
 
 func (ui *UI) DrawButton(s string) bool {
-    id := ui.GenerateNewId()
+    id := ui.GetID(s)
+    ui.claimId(id, s)
+    s = DisplayLabel(s)
 
     // get from outside
     // collect input, refactor
@@ -46,32 +62,44 @@ func (ui *UI) DrawButton(s string) bool {
     maxBoxWidth := 400.0
     maxBoxHeight := 200.0
 
+    availableWidth, _ := ui.Available()
+    maxBoxWidth = min(maxBoxWidth, float64(availableWidth))
 
     // calculate layout
-    boxX := float32(0.0)
-    boxY := float32(0.0)
     maxTextWidth := maxBoxWidth - 2.0 * paddingX
 
-    font := ui.Renderer.Fonts.Get()
+    fonts := ui.Renderer.GetFonts()
+    font := fonts.Get()
     if font == nil {
         // not ready to render font yet
         fmt.Printf("not yet ready to render\n")
         return false
     }
-    placements := PlaceSegments(s, font.Ttf, font.HbFont, font.FontFace, float32(maxTextWidth), 32.0)
+    placements := fonts.Layouts.Place(s, font, float32(maxTextWidth), TextLayoutOptions {
+        Align: AlignCenter,
+    })
 
     maxBoxWidth = min(maxBoxWidth, float64(placements.Width) + paddingX * 2.0)
     maxBoxHeight = min(maxBoxHeight, float64(placements.Height + float32(paddingY) * 2.0))
 
+    box := ui.Place(float32(maxBoxWidth), float32(maxBoxHeight))
+    boxX := box.X
+    boxY := box.Y
+
     // determine state
+    q := NewQuad(boxX, boxY, float32(maxBoxWidth), float32(maxBoxHeight))
+
     var color Color
-    mouseOver := ui.Context.PointerState.IsWithin(boxX, boxY, float32(maxBoxWidth), float32(maxBoxHeight))
-    clicked := mouseOver && ui.Context.PointerState.JustActivated
+    hovered := ui.Context.Hover(id, q)
+    held, clicked := ui.Context.ClickBehavior(id, hovered)
 
     if clicked {
-        color = 0x00ff00ff
         ui.Clicked = id
-    } else if mouseOver {
+    }
+
+    if clicked || held && hovered {
+        color = 0x00ff00ff
+    } else if hovered || held {
         color = 0xff0000ff
     } else {
         color = 0x00ffffff
@@ -79,26 +107,53 @@ func (ui *UI) DrawButton(s string) bool {
 
     // render elements
 
-    q := NewQuad(boxX, boxY, float32(maxBoxWidth), float32(maxBoxHeight))
-    ui.Renderer.MapToClipSpace(&q)
-    DrawQuad(ui.Renderer, q,  color)
+    ui.DrawList.AddRect(q, color)
 
     textX := boxX + float32(paddingX)
     textY := boxY + float32(paddingY)
 
-    atlas := ui.Renderer.GetAtlas()
-    args := RenderTextArgs {
-        FontFace: font.FontFace,
-        GlyphView: atlas.GlyphView,
-        GlyphTex: atlas.GlyphTexture,
+    // long words may not fit into the box, labels,
+    // which do, are left unclipped to keep the batch
+    overflows := float64(placements.Width) > maxTextWidth ||
+        float64(placements.Height) + paddingY * 2.0 > maxBoxHeight
+    if overflows {
+        ui.PushClipRect(q)
+    }
+    ui.DrawList.AddText(placements, NewQuad(textX, textY, 0, 0), ui.Style.TextColor)
+    if overflows {
+        ui.PopClipRect()
     }
-
-    ui.Renderer.RenderText(placements, &args, NewQuad(textX, textY, 0, 0))
 
     // tell state
     return clicked
 }
 
-func End() {}
+// Draws an image registered with Renderer.UploadImage,
+// stretched to the given size
+func (ui *UI) DrawImage(tex TextureId, w, h Float) {
+    q := ui.Place(w, h)
+    ui.DrawList.AddImage(tex, q, NewQuad(0, 0, 1, 1), 0xffffffff)
+}
 
+// Draws text made of spans, wrapped to the available width.
+// Spans without a color take the one of the style.
+func (ui *UI) DrawRichText(text RichText) {
+    font := ui.Renderer.GetFonts().Get()
+    if font == nil {
+        return
+    }
+
+    styled := make(RichText, len(text))
+    for i, s := range text {
+        if s.Color == 0 {
+            s.Color = ui.Style.TextColor
+        }
+        styled[i] = s
+    }
 
+    width, _ := ui.Available()
+    placement := ui.Renderer.GetFonts().Layouts.PlaceRich(styled, font, width, TextLayoutOptions{})
+
+    q := ui.Place(placement.Width, placement.Height)
+    ui.DrawList.AddRichText(placement, styled, q)
+}
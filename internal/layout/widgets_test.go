@@ -0,0 +1,384 @@
+package layout_test
+
+import (
+	"dyiui/internal/golden"
+	. "dyiui/internal/layout"
+	"dyiui/internal/markdown"
+	. "dyiui/internal/render"
+	"dyiui/internal/soft"
+	. "dyiui/internal/text"
+	. "dyiui/internal/types"
+	. "dyiui/internal/ui"
+	"fmt"
+	"image"
+	"path/filepath"
+	"testing"
+)
+
+func PointerAt(x, y float32) PointerState {
+	p := NewPointerState()
+	p.PosX = x
+	p.PosY = y
+	return p
+}
+
+// far away from any widget
+var idle = PointerAt(-100, -100)
+
+// Presses the pointer at x, y during one frame and releases
+// it at rx, ry during the next one
+func pressAndRelease(context *Context, x, y, rx, ry float32, fn func(ui *UI)) {
+	context.PointerState = PointerAt(x, y)
+	context.PointerState.Active = true
+	context.PointerState.JustActivated = true
+	golden.RenderContext(context, fn)
+
+	context.PointerState = PointerAt(rx, ry)
+	context.PointerState.JustReleased = true
+	golden.RenderContext(context, fn)
+}
+
+func TestButton(t *testing.T) {
+	r := golden.Render(200, 80, idle, func(ui *UI) {
+		ui.DrawButton("Button")
+	})
+
+	golden.Assert(t, "button", r.Target)
+}
+
+func TestButtonHovered(t *testing.T) {
+	r := golden.Render(200, 80, PointerAt(10, 10), func(ui *UI) {
+		ui.DrawButton("Button")
+	})
+
+	golden.Assert(t, "button-hovered", r.Target)
+}
+
+func TestButtonRow(t *testing.T) {
+	r := golden.Render(320, 170, idle, func(ui *UI) {
+		ui.BeginRow()
+		ui.DrawButton("One")
+		ui.DrawButton("Two")
+		ui.End()
+		ui.DrawButton("Wrapping label below")
+	})
+
+	golden.Assert(t, "button-row", r.Target)
+}
+
+func TestAtlas(t *testing.T) {
+	r := golden.Render(200, 80, idle, func(ui *UI) {
+		ui.DrawButton("abc")
+	})
+
+	// only the first shelf is in use
+	atlas := r.GetAtlas().Pages[0].Image
+	golden.Assert(t, "atlas", atlas.SubImage(image.Rect(0, 0, 256, 32)))
+}
+
+func TestSDFText(t *testing.T) {
+	r := golden.Render(300, 120, idle, func(ui *UI) {
+		fonts := ui.Renderer.GetFonts()
+		effect := TextEffect{Outline: 1.5, OutlineColor: 0x2060c0ff}
+
+		for i, size := range []float32{16, 40} {
+			font := fonts.Get().WithSize(size)
+			placement := PlaceSegments("Sdf", font, 300, TextLayoutOptions{})
+			ui.DrawList.AddTextSDF(placement, NewQuad(10, float32(10+i*30), 0, 0), 0xffffffff, effect)
+		}
+	})
+
+	golden.Assert(t, "sdf-text", r.Target)
+}
+
+func TestRichText(t *testing.T) {
+	r := golden.Render(300, 120, idle, func(ui *UI) {
+		ui.DrawRichText(RichText{
+			{Text: "Saved "},
+			{Text: "3 files", Color: 0x40c040ff, Underline: true},
+			{Text: " to "},
+			{Text: "disk", Size: 40, Background: 0x404080ff},
+			{Text: ", removed "},
+			{Text: "one", Color: 0xc04040ff, Strikethrough: true},
+		})
+	})
+
+	golden.Assert(t, "rich-text", r.Target)
+}
+
+const markdownSource = `# Changelog
+
+Links are **bold**, *slanted* or ` + "`code`" + `, see [the docs](docs/intro).
+
+- first
+  - nested
+2. numbered
+
+> quoted
+
+` + "```" + `
+go test ./...
+` + "```"
+
+func loadBold(ui *UI) {
+	if _, err := ui.Renderer.GetFonts().Load(filepath.Join(golden.FontsDir(), "Go-Bold.ttf")); err != nil {
+		panic(err)
+	}
+}
+
+func TestMarkdown(t *testing.T) {
+	r := golden.Render(400, 420, idle, func(ui *UI) {
+		loadBold(ui)
+		ui.Markdown(markdownSource)
+	})
+
+	golden.Assert(t, "markdown", r.Target)
+}
+
+func TestMarkdownIsNotPlacedAgainEveryFrame(t *testing.T) {
+	r := soft.NewRenderer(400, 420, golden.FixtureFont())
+	context := &Context{Width: 400, Height: 420, PointerState: idle}
+
+	frame := func() {
+		r.BeginFrame()
+		ui := NewUI(context, r)
+		ui.Begin(400, 420)
+		ui.Markdown(markdownSource)
+		ui.End()
+		r.FinishFrame(ui.DrawList)
+	}
+
+	frame()
+	stats := r.Fonts.Layouts.Stats
+	frame()
+
+	if r.Fonts.Layouts.Stats.Misses != stats.Misses {
+		t.Fatalf("Expected the second frame to reuse all layouts, got %d more misses", r.Fonts.Layouts.Stats.Misses-stats.Misses)
+	}
+
+	// every block is looked up at least once
+	blocks := len(markdown.Parse(markdownSource))
+	if hits := r.Fonts.Layouts.Stats.Hits - stats.Hits; hits < uint(blocks) {
+		t.Fatalf("Expected the blocks to be placed through the cache, got %d hits for %d blocks", hits, blocks)
+	}
+}
+
+func TestMarkdownLinkReportsClick(t *testing.T) {
+	click := func(x, y float32) (string, ElementId) {
+		var clicked string
+		var id ElementId
+
+		pressAndRelease(&Context{Width: 400, Height: 420}, x, y, x, y, func(ui *UI) {
+			loadBold(ui)
+			if href := ui.Markdown(markdownSource); href != "" {
+				clicked, id = href, ui.Clicked
+			}
+		})
+
+		return clicked, id
+	}
+
+	// within "the docs" of the second block, see markdown.png
+	if clicked, id := click(200, 122); clicked != "docs/intro" || id == 0 {
+		t.Fatalf("Expected a click on the link to be reported, got %q", clicked)
+	}
+
+	// on "see" in front of it
+	if clicked, _ := click(120, 122); clicked != "" {
+		t.Fatalf("Expected a click beside the link not to be reported, got %q", clicked)
+	}
+}
+
+func TestMarkdownLinksWithTheSameTargetAreClickedApart(t *testing.T) {
+	src := "[first](x) link\n\n[second](x) link"
+
+	var lineHeight float32
+	golden.Render(400, 200, idle, func(ui *UI) {
+		lineHeight = ui.Renderer.GetFonts().Get().Metrics.LineHeight()
+	})
+
+	// both links start their paragraph, which are spaced apart
+	for _, y := range []float32{lineHeight / 2, lineHeight*1.5 + 8} {
+		clicked := ""
+		pressAndRelease(&Context{Width: 400, Height: 200}, 4, y, 4, y, func(ui *UI) {
+			if href := ui.Markdown(src); href != "" {
+				clicked = href
+			}
+		})
+
+		if clicked != "x" {
+			t.Errorf("Expected a click on the link at %f to be reported, got %q", y, clicked)
+		}
+	}
+}
+
+func TestClipRectsIntersect(t *testing.T) {
+	r := golden.Render(200, 200, idle, func(ui *UI) {
+		ui.PushClipRect(NewQuad(0, 0, 150, 200))
+		ui.PushClipRect(NewQuad(50, 20, 150, 100))
+
+		if clip := ui.ClipRect(); clip != NewQuad(50, 20, 100, 100) {
+			t.Errorf("Expected the clip rects to intersect, got %v", clip)
+		}
+
+		ui.DrawButton("Clipped button")
+		ui.PopClipRect()
+		ui.DrawButton("Half of a button")
+		ui.PopClipRect()
+
+		if clip := ui.ClipRect(); clip != NO_CLIP {
+			t.Errorf("Expected nothing to be clipped, got %v", clip)
+		}
+	})
+
+	golden.Assert(t, "clip-rect", r.Target)
+}
+
+func TestButtonsShareABatch(t *testing.T) {
+	var batches []Batch
+	golden.Render(800, 80, idle, func(ui *UI) {
+		ui.BeginRow()
+		for i := 0; i < 10; i++ {
+			ui.DrawButton(fmt.Sprintf("%d", i))
+		}
+		ui.End()
+		batches = ui.DrawList.Batches()
+	})
+
+	if len(batches) != 1 {
+		t.Fatalf("Expected a row of buttons to be drawn at once, got %d batches", len(batches))
+	}
+}
+
+func TestClippedWidgetsAreNotHit(t *testing.T) {
+	clicked := false
+	pressAndRelease(&Context{Width: 200, Height: 80}, 20, 20, 20, 20, func(ui *UI) {
+		ui.PushClipRect(NewQuad(50, 0, 150, 80))
+		clicked = clicked || ui.DrawButton("Button")
+		ui.PopClipRect()
+	})
+
+	if clicked {
+		t.Errorf("Expected the clipped part of the button not to be hit")
+	}
+
+	pressAndRelease(&Context{Width: 200, Height: 80}, 20, 20, 20, 20, func(ui *UI) {
+		clicked = clicked || ui.DrawButton("Button")
+	})
+
+	if !clicked {
+		t.Errorf("Expected the button to be hit without a clip")
+	}
+}
+
+func scrollContent(ui *UI) bool {
+	ui.BeginScrollArea("##log", 200, 120)
+	for i := 0; i < 6; i++ {
+		ui.DrawButton(fmt.Sprintf("Entry %d", i))
+	}
+	return ui.EndScrollArea()
+}
+
+func TestScrollAreaScrollsWithWheel(t *testing.T) {
+	context := &Context{Width: 240, Height: 160, PointerState: PointerAt(50, 50)}
+	context.PointerState.WheelY = -1
+
+	var scrolled bool
+	golden.RenderContext(context, func(ui *UI) {
+		scrolled = scrollContent(ui)
+	})
+
+	if !scrolled || context.PointerState.WheelY != 0 {
+		t.Fatalf("Expected the area to take the wheel")
+	}
+
+	r := golden.RenderContext(context, func(ui *UI) {
+		scrollContent(ui)
+	})
+
+	golden.Assert(t, "scroll-area", r.Target)
+}
+
+func TestScrollAreaStopsAtTheEnd(t *testing.T) {
+	context := &Context{Width: 240, Height: 160, PointerState: PointerAt(50, 50)}
+
+	var state *ScrollState
+	for i := 0; i < 20; i++ {
+		context.PointerState.WheelY = -1
+		golden.RenderContext(context, func(ui *UI) {
+			scrollContent(ui)
+			state = GetState[ScrollState](ui.Context, ui.GetID("##log"))
+		})
+	}
+
+	if state.OffsetY != state.ContentHeight-120 {
+		t.Fatalf("Expected to scroll to the end of the content, got %f of %f", state.OffsetY, state.ContentHeight)
+	}
+}
+
+func TestScrollbarThumbCanBeDragged(t *testing.T) {
+	context := &Context{Width: 240, Height: 160}
+
+	frame := func(x, y float32, active, justActivated bool) *ScrollState {
+		context.PointerState = PointerAt(x, y)
+		context.PointerState.Active = active
+		context.PointerState.JustActivated = justActivated
+
+		var state *ScrollState
+		golden.RenderContext(context, func(ui *UI) {
+			scrollContent(ui)
+			state = GetState[ScrollState](ui.Context, ui.GetID("##log"))
+		})
+		return state
+	}
+
+	// measures the content, the thumb is at the top
+	frame(0, 0, false, false)
+	frame(195, 5, true, true)
+	state := frame(195, 45, true, false)
+
+	if state.OffsetY <= 0 {
+		t.Fatalf("Expected dragging the thumb down to scroll, got %f", state.OffsetY)
+	}
+
+	offset := state.OffsetY
+	state = frame(195, 200, false, false)
+	if state.Dragging != NoAxis || state.OffsetY != offset {
+		t.Fatalf("Expected the drag to end on release")
+	}
+}
+
+func TestInputText(t *testing.T) {
+	text := "Some text"
+
+	r := golden.Render(400, 80, idle, func(ui *UI) {
+		ui.InputText("Label", &text)
+	})
+
+	golden.Assert(t, "input-text", r.Target)
+}
+
+func TestInputTextScrollsToCaret(t *testing.T) {
+	text := "A text, which is much too long for the input"
+
+	// clicking focuses the input and places the caret
+	click := PointerAt(230, 20)
+	click.Active = true
+	click.JustActivated = true
+
+	r := golden.Render(400, 80, click, func(ui *UI) {
+		ui.Context.Keyboard.QueueKey(KeyEnd, KeyPress, 0)
+		ui.Context.Keyboard.NewFrame()
+		ui.InputText("##long", &text)
+	})
+
+	golden.Assert(t, "input-text-scrolled", r.Target)
+}
+
+func TestButtonWithFallbackFont(t *testing.T) {
+	r := golden.Render(200, 80, idle, func(ui *UI) {
+		ui.DrawButton("Go 中")
+	})
+
+	golden.Assert(t, "button-fallback", r.Target)
+}
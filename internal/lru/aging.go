@@ -0,0 +1,64 @@
+package lru
+
+// Keeps entries for as long as they are used every few frames,
+// rather than limiting their number. The zero value is ready to use.
+type AgingCache[K comparable, T any] struct {
+    frame uint64
+    entries map[K]*agingEntry[T]
+}
+
+type agingEntry[T any] struct {
+    value T
+    // frame of the last lookup
+    used uint64
+}
+
+// Looks up the value and marks it as used in this frame
+func (cache *AgingCache[K, T]) Get(key K) (T, bool) {
+    e := cache.entries[key]
+    if e == nil {
+        var zero T
+        return zero, false
+    }
+
+    e.used = cache.frame
+    return e.value, true
+}
+
+// Whether there is a value, without marking it as used
+func (cache *AgingCache[K, T]) Has(key K) bool {
+    _, ok := cache.entries[key]
+    return ok
+}
+
+// Stores or replaces the value, as used in this frame
+func (cache *AgingCache[K, T]) Store(key K, v T) {
+    if cache.entries == nil {
+        cache.entries = make(map[K]*agingEntry[T])
+    }
+    cache.entries[key] = &agingEntry[T] { v, cache.frame }
+}
+
+func (cache *AgingCache[K, T]) Delete(key K) {
+    delete(cache.entries, key)
+}
+
+func (cache *AgingCache[K, T]) Len() int {
+    return len(cache.entries)
+}
+
+// Advances to the next frame and drops the entries, which were
+// not used during the last maxAge frames. Returns how many.
+func (cache *AgingCache[K, T]) NextFrame(maxAge uint64) uint {
+    cache.frame += 1
+
+    dropped := uint(0)
+    for key, e := range cache.entries {
+        if cache.frame - e.used > maxAge {
+            delete(cache.entries, key)
+            dropped += 1
+        }
+    }
+
+    return dropped
+}
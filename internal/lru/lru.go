@@ -1,109 +1,142 @@
 package lru
 
-import "github.com/benoitkugler/textlayout/fonts"
+type Entries[K comparable, T any] map[K]*LRUCacheEntry[K, T]
 
-type Key = fonts.GID
-type Entries[T any] map[Key]*LRUCacheEntry[T]
-
-type LRUCacheEntry[T any] struct {
-    key Key
+type LRUCacheEntry[K comparable, T any] struct {
+    key K
 	value T
-	newer *LRUCacheEntry[T]
-	older *LRUCacheEntry[T]
+	newer *LRUCacheEntry[K, T]
+	older *LRUCacheEntry[K, T]
+}
+
+// Counts lookups and evictions, e.g. to tell
+// whether the cache is large enough
+type Stats struct {
+    Hits uint
+    Misses uint
+    Evictions uint
 }
 
-type LRUCache[T any] struct {
-	entries Entries[T]
-	oldest  *LRUCacheEntry[T]
-	newest  *LRUCacheEntry[T]
-    size    uint
+type LRUCache[K comparable, T any] struct {
+	entries Entries[K, T]
+	oldest  *LRUCacheEntry[K, T]
+	newest  *LRUCacheEntry[K, T]
     maxSize uint
+
+    // called with the least recently used entry,
+    // when it is dropped to make room for a new one
+    OnEvict func(key K, value T)
+    Stats Stats
 }
 
-func NewLRUCache[T any](s uint) *LRUCache[T] {
-    return &LRUCache[T] {
-        entries: make(Entries[T]),
+func NewLRUCache[K comparable, T any](s uint) *LRUCache[K, T] {
+    return &LRUCache[K, T] {
+        entries: make(Entries[K, T]),
         oldest: nil,
         newest: nil,
-        size: 0,
         maxSize: s,
     }
 }
 
-// A helper functions, that removed
-// and element from the linked list and
-// reconnects open ends
-// but the enrty itself still points
-// to its siblings and needs correction
-func (e *LRUCacheEntry[T]) unplug() {
-	older := e.older
-	newer := e.newer
-
-	if older != nil {
-		older = newer
+func (cache *LRUCache[K, T]) Len() int {
+    return len(cache.entries)
+}
+
+// Removes the entry from the linked list and
+// reconnects its siblings with each other
+func (cache *LRUCache[K, T]) unplug(e *LRUCacheEntry[K, T]) {
+	if e.older != nil {
+		e.older.newer = e.newer
+	} else {
+		cache.oldest = e.newer
+	}
+
+	if e.newer != nil {
+		e.newer.older = e.older
+	} else {
+		cache.newest = e.older
+	}
+
+	e.older = nil
+	e.newer = nil
+}
+
+// Inserts an unplugged entry as the newest one
+func (cache *LRUCache[K, T]) pushNewest(e *LRUCacheEntry[K, T]) {
+	e.older = cache.newest
+	e.newer = nil
+
+	if cache.newest != nil {
+		cache.newest.newer = e
 	}
+	cache.newest = e
 
-	if newer != nil {
-		newer.older = older
+	if cache.oldest == nil {
+		cache.oldest = e
 	}
 }
 
-func (cache *LRUCache[T]) Get(key Key) *T {
+// Looks up the value and marks it as the most recently used one.
+// The pointer is only valid until the entry is evicted.
+func (cache *LRUCache[K, T]) Get(key K) *T {
 	e := cache.entries[key]
 
 	if e == nil {
+		cache.Stats.Misses += 1
 		return nil
 	}
 
-    // this introduces two invariants for the rest of the function
-    // 1) the entry is not the newest
-    // 2) there is more than a single entry in the cache (otherwise 1) would not hold)
-    if e == cache.newest {
-        return &e.value
-    }
+	cache.Stats.Hits += 1
 
-	e.unplug()
-
-	e.newer = nil
-    e.older = cache.newest
-    cache.newest.newer = e
-    cache.newest = e
+	if e != cache.newest {
+		cache.unplug(e)
+		cache.pushNewest(e)
+	}
 
 	return &e.value
 }
 
-func (cache *LRUCache[T]) Store(key Key, v T) {
-    e := cache.entries[key]
-
-    if e != nil {
-        e.unplug()
-    } else {
-        e = &LRUCacheEntry[T] {
-            value: v,
-            older: cache.newest,
-            key: key,
-        }
-
-        // increase counter 
-        // or remove oldest one
-        if cache.size < cache.maxSize {
-            cache.size += 1
-        } else if cache.oldest != nil {
-            oldest := cache.oldest
-
-            if oldest.newer != nil {
-                cache.oldest = oldest.newer
-                cache.oldest.newer = nil
-            }
-
-            delete(cache.entries, oldest.key)
-        }
-
-        cache.entries[key] = e
+// Stores or updates the value, evicting the least
+// recently used entry, if the cache is full
+func (cache *LRUCache[K, T]) Store(key K, v T) {
+    if e := cache.entries[key]; e != nil {
+        e.value = v
+        cache.unplug(e)
+        cache.pushNewest(e)
+        return
+    }
+
+    if cache.IsFull() {
+        cache.EvictOldest()
     }
-    cache.newest = e
-    if cache.oldest == nil {
-        cache.oldest = e
+
+    e := &LRUCacheEntry[K, T] {
+        key: key,
+        value: v,
     }
+
+    cache.entries[key] = e
+    cache.pushNewest(e)
 }
 
+func (cache *LRUCache[K, T]) IsFull() bool {
+    return uint(len(cache.entries)) >= cache.maxSize
+}
+
+// Evicts the least recently used entry, e.g. to reclaim
+// its resources before storing a new one
+func (cache *LRUCache[K, T]) EvictOldest() {
+    if cache.oldest != nil {
+        cache.evict(cache.oldest)
+    }
+}
+
+func (cache *LRUCache[K, T]) evict(e *LRUCacheEntry[K, T]) {
+    cache.unplug(e)
+    delete(cache.entries, e.key)
+    cache.Stats.Evictions += 1
+
+    if cache.OnEvict != nil {
+        cache.OnEvict(e.key, e.value)
+    }
+}
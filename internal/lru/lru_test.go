@@ -6,7 +6,7 @@ import (
 
 func TestSimpleAdd(t *testing.T) {
     toInsert := 1
-    c := NewLRUCache[int](3)
+    c := NewLRUCache[rune, int](3)
     c.Store('r', toInsert)
     v := c.Get('r')
 
@@ -17,7 +17,7 @@ func TestSimpleAdd(t *testing.T) {
 
 func TestEntryReplaced(t *testing.T) {
     toInsert := 1
-    c := NewLRUCache[int](3)
+    c := NewLRUCache[rune, int](3)
     c.Store('r', toInsert)
     v := c.Get('r')
 
@@ -37,7 +37,7 @@ func TestEntryReplaced(t *testing.T) {
 
 func TestEntryNotReplacedSinceUsed(t *testing.T) {
     toInsert := 1
-    c := NewLRUCache[int](3)
+    c := NewLRUCache[rune, int](3)
     c.Store('r', toInsert)
     v := c.Get('r')
 
@@ -51,12 +51,111 @@ func TestEntryNotReplacedSinceUsed(t *testing.T) {
     c.Store('c', 4)
 
     v = c.Get('r')
-    if v != nil && *v == toInsert {
-        t.Fatal("Failed to replace value, when cache is filled")
+    if v == nil || *v != toInsert {
+        t.Fatal("Replaced value, although it was used recently")
     }
 
     v = c.Get('a')
-    if v != nil && *v == 0 {
-        t.Fatal("Failed to replace value, when cache is filled")
+    if v != nil {
+        t.Fatal("Failed to replace least recently used value, when cache is filled")
+    }
+}
+
+func TestStoreUpdatesExistingValue(t *testing.T) {
+    c := NewLRUCache[rune, int](2)
+    c.Store('r', 1)
+    c.Store('a', 2)
+    c.Store('r', 3)
+
+    if v := c.Get('r'); v == nil || *v != 3 {
+        t.Fatalf("Expected value to be updated, got %v", v)
+    }
+
+    if c.Len() != 2 {
+        t.Fatalf("Expected update not to add an entry, got %d", c.Len())
+    }
+
+    // the update counts as use, so 'a' is the oldest now
+    c.Store('b', 4)
+    if c.Get('a') != nil || c.Get('r') == nil {
+        t.Fatal("Expected 'a' to be evicted")
+    }
+}
+
+func TestOrderSurvivesRepeatedUse(t *testing.T) {
+    c := NewLRUCache[rune, int](3)
+    c.Store('a', 1)
+    c.Store('b', 2)
+    c.Store('c', 3)
+
+    // move entries out of the middle and the end of the list
+    c.Get('b')
+    c.Get('a')
+    c.Get('b')
+
+    var evicted []rune
+    c.OnEvict = func(key rune, value int) {
+        evicted = append(evicted, key)
+    }
+
+    c.Store('d', 4)
+    c.Store('e', 5)
+    c.Store('f', 6)
+
+    if string(evicted) != "cab" {
+        t.Fatalf("Expected eviction in order of last use, got '%s'", string(evicted))
+    }
+}
+
+func TestOnEvictReceivesValue(t *testing.T) {
+    c := NewLRUCache[rune, int](1)
+
+    var key rune
+    var value int
+    c.OnEvict = func(k rune, v int) {
+        key = k
+        value = v
+    }
+
+    c.Store('r', 1)
+    c.Store('a', 2)
+
+    if key != 'r' || value != 1 {
+        t.Fatalf("Expected ('r', 1) to be evicted, got (%q, %d)", key, value)
+    }
+}
+
+func TestStats(t *testing.T) {
+    c := NewLRUCache[rune, int](1)
+    c.Store('r', 1)
+    c.Get('r')
+    c.Get('a')
+    c.Store('a', 2)
+    c.Get('r')
+
+    expected := Stats{Hits: 1, Misses: 2, Evictions: 1}
+    if c.Stats != expected {
+        t.Fatalf("Expected %+v, got %+v", expected, c.Stats)
+    }
+}
+
+func TestAgingCacheDropsUnusedEntries(t *testing.T) {
+    var c AgingCache[rune, int]
+    c.Store('a', 1)
+    c.Store('b', 2)
+
+    for i := 0; i < 2; i++ {
+        c.Get('a')
+        if dropped := c.NextFrame(2); dropped != 0 {
+            t.Fatalf("Dropped %d entries too early", dropped)
+        }
+    }
+
+    if dropped := c.NextFrame(2); dropped != 1 || c.Has('b') {
+        t.Fatalf("Expected the unused entry to be dropped, dropped %d", dropped)
+    }
+
+    if v, ok := c.Get('a'); !ok || v != 1 {
+        t.Fatalf("Expected the used entry to be kept")
     }
 }
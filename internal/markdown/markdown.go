@@ -0,0 +1,438 @@
+/*
+Package markdown parses the subset of markdown, which help texts
+and changelogs are written in: headings, paragraphs, bullet and
+numbered lists, block quotes and fenced code blocks, with emphasis,
+inline code and links within their text.
+
+Anything else is kept as plain text, so no input is rejected.
+*/
+package markdown
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+type BlockKind int
+
+const (
+	Paragraph BlockKind = iota
+	Heading
+	ListItem
+	CodeBlock
+)
+
+type Block struct {
+	Kind BlockKind
+	// 1 to 6 for headings, nesting of list items starting at 0
+	Level int
+	// whether the list item is numbered rather than a bullet
+	Ordered bool
+	Number  int
+	// of the block quotes the block is within
+	Quote int
+	// text of code blocks, lines are separated by \n
+	Code string
+	// info string after the opening fence, e.g. the language
+	Info    string
+	Inlines []Inline
+}
+
+type Style int
+
+const (
+	Emphasis Style = 1 << iota
+	Strong
+	Code
+	Link
+)
+
+// A piece of text, all of which has the same style
+type Inline struct {
+	Text  string
+	Style Style
+	// target of links
+	Href string
+}
+
+// The text of the inlines without their markup
+func PlainText(inlines []Inline) string {
+	var sb strings.Builder
+	for _, in := range inlines {
+		sb.WriteString(in.Text)
+	}
+	return sb.String()
+}
+
+// Spaces a level of list items is indented by
+const LIST_INDENT = 2
+
+// Splits the source into blocks
+func Parse(src string) []Block {
+	src = strings.ReplaceAll(src, "\r\n", "\n")
+	return parseLines(strings.Split(src, "\n"), 0)
+}
+
+func parseLines(lines []string, quote int) []Block {
+	var blocks []Block
+	// lines of the paragraph or list item being collected
+	var text []string
+	var open *Block
+
+	flush := func() {
+		if open != nil {
+			open.Inlines = ParseInlines(strings.Join(text, " "))
+			blocks = append(blocks, *open)
+		}
+		open = nil
+		text = nil
+	}
+
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		trimmed := strings.TrimSpace(line)
+		indent := len(line) - len(strings.TrimLeft(line, " \t"))
+
+		if trimmed == "" {
+			flush()
+			continue
+		}
+
+		if fence, ok := codeFence(trimmed); ok {
+			flush()
+
+			var code []string
+			i += 1
+			for ; i < len(lines); i++ {
+				if strings.HasPrefix(strings.TrimSpace(lines[i]), fence) {
+					break
+				}
+				code = append(code, strings.TrimPrefix(lines[i], strings.Repeat(" ", indent)))
+			}
+
+			blocks = append(blocks, Block{
+				Kind:  CodeBlock,
+				Quote: quote,
+				Code:  strings.Join(code, "\n"),
+				Info:  strings.TrimSpace(strings.TrimLeft(trimmed, fence[:1])),
+			})
+			continue
+		}
+
+		if strings.HasPrefix(trimmed, ">") {
+			flush()
+
+			var quoted []string
+			for ; i < len(lines); i++ {
+				t := strings.TrimSpace(lines[i])
+				if !strings.HasPrefix(t, ">") {
+					break
+				}
+				t = strings.TrimPrefix(t, ">")
+				quoted = append(quoted, strings.TrimPrefix(t, " "))
+			}
+			i -= 1
+
+			blocks = append(blocks, parseLines(quoted, quote+1)...)
+			continue
+		}
+
+		if level, rest, ok := heading(trimmed); ok {
+			flush()
+			blocks = append(blocks, Block{
+				Kind:    Heading,
+				Level:   level,
+				Quote:   quote,
+				Inlines: ParseInlines(rest),
+			})
+			continue
+		}
+
+		if ordered, number, rest, ok := listMarker(trimmed); ok {
+			flush()
+			open = &Block{
+				Kind:    ListItem,
+				Level:   indent / LIST_INDENT,
+				Ordered: ordered,
+				Number:  number,
+				Quote:   quote,
+			}
+			text = []string{rest}
+			continue
+		}
+
+		// lazy continuation of the open paragraph or list item
+		if open == nil {
+			open = &Block{Kind: Paragraph, Quote: quote}
+		}
+		text = append(text, trimmed)
+	}
+
+	flush()
+	return blocks
+}
+
+// The fence of a fenced code block, at least three backticks or tildes
+func codeFence(line string) (string, bool) {
+	for _, f := range []string{"```", "~~~"} {
+		if strings.HasPrefix(line, f) {
+			n := len(line) - len(strings.TrimLeft(line, f[:1]))
+			return line[:n], true
+		}
+	}
+	return "", false
+}
+
+func heading(line string) (int, string, bool) {
+	level := 0
+	for level < len(line) && line[level] == '#' {
+		level += 1
+	}
+
+	if level == 0 || level > 6 {
+		return 0, "", false
+	}
+
+	rest := line[level:]
+	if rest != "" && rest[0] != ' ' {
+		return 0, "", false
+	}
+
+	// closing hashes are optional
+	rest = strings.TrimSpace(rest)
+	rest = strings.TrimSpace(strings.TrimRight(rest, "#"))
+
+	return level, rest, true
+}
+
+// Tells whether the item is numbered and its number
+func listMarker(line string) (bool, int, string, bool) {
+	if len(line) >= 2 && strings.ContainsRune("-*+", rune(line[0])) && line[1] == ' ' {
+		return false, 0, strings.TrimSpace(line[2:]), true
+	}
+
+	digits := 0
+	for digits < len(line) && digits < 9 && line[digits] >= '0' && line[digits] <= '9' {
+		digits += 1
+	}
+
+	if digits == 0 || digits+1 >= len(line) {
+		return false, 0, "", false
+	}
+
+	if (line[digits] != '.' && line[digits] != ')') || line[digits+1] != ' ' {
+		return false, 0, "", false
+	}
+
+	number, _ := strconv.Atoi(line[:digits])
+	return true, number, strings.TrimSpace(line[digits+2:]), true
+}
+
+// Splits text into inlines by its emphasis, code spans and links.
+// Delimiters without a matching one are kept as text.
+func ParseInlines(text string) []Inline {
+	p := inlineParser{rs: []rune(text)}
+	p.parse(0, len(p.rs), 0, "")
+	return p.inlines
+}
+
+type inlineParser struct {
+	rs      []rune
+	inlines []Inline
+}
+
+// Appends text to the last inline, if its style is the same
+func (p *inlineParser) text(s string, style Style, href string) {
+	if s == "" {
+		return
+	}
+
+	if n := len(p.inlines); n > 0 {
+		last := &p.inlines[n-1]
+		if last.Style == style && last.Href == href {
+			last.Text += s
+			return
+		}
+	}
+
+	p.inlines = append(p.inlines, Inline{Text: s, Style: style, Href: href})
+}
+
+func (p *inlineParser) parse(start, end int, style Style, href string) {
+	rs := p.rs
+	var plain []rune
+
+	emit := func() {
+		p.text(string(plain), style, href)
+		plain = plain[:0]
+	}
+
+	for i := start; i < end; {
+		r := rs[i]
+
+		switch {
+		case r == '\\' && i+1 < end && isEscapable(rs[i+1]):
+			plain = append(plain, rs[i+1])
+			i += 2
+			continue
+
+		case r == '`':
+			n := runLength(rs, i, end, '`')
+			if close := find(rs, i+n, end, strings.Repeat("`", n)); close >= 0 {
+				emit()
+				code := strings.TrimSpace(string(rs[i+n : close]))
+				p.text(code, style|Code, href)
+				i = close + n
+				continue
+			}
+
+		case r == '[' && style&Link == 0:
+			if textEnd, target, next, ok := link(rs, i, end); ok {
+				emit()
+				p.parse(i+1, textEnd, style|Link, target)
+				i = next
+				continue
+			}
+
+		case r == '*' || r == '_':
+			n := min(runLength(rs, i, end, r), 2)
+			delim := strings.Repeat(string(r), n)
+			s := Emphasis
+			if n == 2 {
+				s = Strong
+			}
+
+			if canOpen(rs, i, n, end) {
+				if close := findClosing(rs, i+n, end, delim); close >= 0 {
+					emit()
+					p.parse(i+n, close, style|s, href)
+					i = close + n
+					continue
+				}
+			}
+		}
+
+		plain = append(plain, r)
+		i += 1
+	}
+
+	emit()
+}
+
+func runLength(rs []rune, i, end int, r rune) int {
+	n := 0
+	for i+n < end && rs[i+n] == r {
+		n += 1
+	}
+	return n
+}
+
+// Index of the first occurrence of s in rs[start:end], or -1
+func find(rs []rune, start, end int, s string) int {
+	needle := []rune(s)
+
+	for i := start; i+len(needle) <= end; i++ {
+		if string(rs[i:i+len(needle)]) == s {
+			return i
+		}
+	}
+	return -1
+}
+
+// Delimiters open emphasis, when followed by text.
+// Underscores within words do not count.
+func canOpen(rs []rune, i, n, end int) bool {
+	if i+n >= end || unicode.IsSpace(rs[i+n]) {
+		return false
+	}
+	if rs[i] == '_' && i > 0 && isWordRune(rs[i-1]) {
+		return false
+	}
+	return true
+}
+
+func canClose(rs []rune, i, n, end int) bool {
+	if i == 0 || unicode.IsSpace(rs[i-1]) {
+		return false
+	}
+	if rs[i] == '_' && i+n < end && isWordRune(rs[i+n]) {
+		return false
+	}
+	return true
+}
+
+func isEscapable(r rune) bool {
+	return unicode.IsPunct(r) || unicode.IsSymbol(r)
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// Closing delimiter, which is not part of a longer run or a code span
+func findClosing(rs []rune, start, end int, delim string) int {
+	r := rune(delim[0])
+	n := len(delim)
+
+	for i := start; i < end; i++ {
+		switch rs[i] {
+		case '\\':
+			i += 1
+		case '`':
+			m := runLength(rs, i, end, '`')
+			if close := find(rs, i+m, end, strings.Repeat("`", m)); close >= 0 {
+				i = close + m - 1
+			}
+		case r:
+			m := runLength(rs, i, end, r)
+			if m == n && canClose(rs, i, n, end) && i > start {
+				return i
+			}
+			i += m - 1
+		}
+	}
+
+	return -1
+}
+
+// Parses [text](target) starting at the bracket. Returns the end
+// of the text, the target and where the link ends.
+func link(rs []rune, start, end int) (int, string, int, bool) {
+	depth := 0
+	textEnd := -1
+
+	for i := start; i < end; i++ {
+		if rs[i] == '\\' {
+			i += 1
+			continue
+		}
+
+		if rs[i] == '[' {
+			depth += 1
+		} else if rs[i] == ']' {
+			depth -= 1
+			if depth == 0 {
+				textEnd = i
+				break
+			}
+		}
+	}
+
+	if textEnd < 0 || textEnd+1 >= end || rs[textEnd+1] != '(' {
+		return 0, "", 0, false
+	}
+
+	close := find(rs, textEnd+2, end, ")")
+	if close < 0 {
+		return 0, "", 0, false
+	}
+
+	target := strings.TrimSpace(string(rs[textEnd+2 : close]))
+	// an optional title after the target is dropped
+	if fields := strings.Fields(target); len(fields) > 0 {
+		target = fields[0]
+	}
+
+	return textEnd, strings.Trim(target, "<>"), close + 1, true
+}
@@ -0,0 +1,71 @@
+package markdown
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseBlocks(t *testing.T) {
+	src := "# Title\n\nSome text\ncontinued.\n\n- one\n  - nested\n2. two\n\n> quoted\n> text\n\n```go\nfunc main() {\n}\n```\n"
+	blocks := Parse(src)
+
+	kinds := []BlockKind{Heading, Paragraph, ListItem, ListItem, ListItem, Paragraph, CodeBlock}
+	if len(blocks) != len(kinds) {
+		t.Fatalf("Expected %d blocks, got %+v", len(kinds), blocks)
+	}
+
+	for i, b := range blocks {
+		if b.Kind != kinds[i] {
+			t.Errorf("Expected block %d to be of kind %d, got %d", i, kinds[i], b.Kind)
+		}
+	}
+
+	if blocks[0].Level != 1 || PlainText(blocks[0].Inlines) != "Title" {
+		t.Errorf("Unexpected heading %+v", blocks[0])
+	}
+
+	if PlainText(blocks[1].Inlines) != "Some text continued." {
+		t.Errorf("Expected the lines of the paragraph to be joined, got %q", PlainText(blocks[1].Inlines))
+	}
+
+	if blocks[3].Level != 1 || blocks[4].Level != 0 || !blocks[4].Ordered || blocks[4].Number != 2 {
+		t.Errorf("Unexpected list items %+v %+v", blocks[3], blocks[4])
+	}
+
+	if blocks[5].Quote != 1 || PlainText(blocks[5].Inlines) != "quoted text" {
+		t.Errorf("Unexpected quote %+v", blocks[5])
+	}
+
+	if blocks[6].Info != "go" || blocks[6].Code != "func main() {\n}" {
+		t.Errorf("Unexpected code block %+v", blocks[6])
+	}
+}
+
+func TestParseInlines(t *testing.T) {
+	inlines := ParseInlines("a *b* **c** `d*` [e **f**](http://x) snake_case_name")
+
+	expected := []Inline{
+		{Text: "a "},
+		{Text: "b", Style: Emphasis},
+		{Text: " "},
+		{Text: "c", Style: Strong},
+		{Text: " "},
+		{Text: "d*", Style: Code},
+		{Text: " "},
+		{Text: "e ", Style: Link, Href: "http://x"},
+		{Text: "f", Style: Link | Strong, Href: "http://x"},
+		{Text: " snake_case_name"},
+	}
+
+	if !reflect.DeepEqual(inlines, expected) {
+		t.Fatalf("Expected %+v, got %+v", expected, inlines)
+	}
+}
+
+func TestUnmatchedDelimitersStayText(t *testing.T) {
+	inlines := ParseInlines("2 * 3 = 6, **open and [no link] \\*escaped\\*")
+
+	if len(inlines) != 1 || inlines[0].Text != "2 * 3 = 6, **open and [no link] *escaped*" {
+		t.Fatalf("Expected plain text, got %+v", inlines)
+	}
+}
@@ -0,0 +1,61 @@
+package render
+
+import (
+	. "dyiui/internal/types"
+)
+
+// Consecutive commands, which can be issued
+// with a single draw call
+type Batch struct {
+	Texture TextureId
+	Clip    Quad
+	// drawn from distance fields with the effect
+	SDF         bool
+	Effect      TextEffect
+	IndexOffset int
+	IndexCount  int
+}
+
+// Merges commands as long as neither the texture nor the clip
+// changes. Since rects and glyphs both sample the atlas, a frame
+// without images and clips ends up as a single batch. Text drawn
+// from distance fields is only merged with text of the same effect.
+func (dl *DrawList) Batches() []Batch {
+	var batches []Batch
+	clip := NO_CLIP
+
+	for _, cmd := range dl.Commands {
+		if cmd.Kind == ClipCommand {
+			clip = cmd.Clip
+			continue
+		}
+
+		if cmd.IndexCount == 0 {
+			continue
+		}
+
+		sdf := cmd.Kind == SDFTextCommand
+
+		if n := len(batches); n > 0 {
+			last := &batches[n-1]
+			contiguous := last.IndexOffset+last.IndexCount == cmd.IndexOffset
+			sameShading := last.SDF == sdf && last.Effect == cmd.Effect
+
+			if contiguous && last.Texture == cmd.Texture && last.Clip == clip && sameShading {
+				last.IndexCount += cmd.IndexCount
+				continue
+			}
+		}
+
+		batches = append(batches, Batch{
+			Texture:     cmd.Texture,
+			Clip:        clip,
+			SDF:         sdf,
+			Effect:      cmd.Effect,
+			IndexOffset: cmd.IndexOffset,
+			IndexCount:  cmd.IndexCount,
+		})
+	}
+
+	return batches
+}
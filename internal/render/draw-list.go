@@ -0,0 +1,402 @@
+package render
+
+import (
+	. "dyiui/internal/atlas"
+	. "dyiui/internal/color"
+	. "dyiui/internal/text"
+	. "dyiui/internal/types"
+	"fmt"
+	"math"
+	"strings"
+)
+
+type CommandKind = int
+
+const (
+	RectCommand CommandKind = iota
+	TextCommand
+	ImageCommand
+	// restricts all following commands to Clip
+	ClipCommand
+	// glyphs drawn from distance fields, see AddTextSDF
+	SDFTextCommand
+)
+
+type TextureKind = int
+
+const (
+	// a page of the glyph atlas, which also
+	// provides the white texel for plain rects
+	AtlasTexture TextureKind = iota
+	// an image registered with Renderer.UploadImage
+	ImageTexture
+)
+
+type TextureId struct {
+	Kind  TextureKind
+	Index int
+}
+
+// Clip of commands, which are not restricted
+var NO_CLIP = NewQuad(0, 0, math.MaxFloat32, math.MaxFloat32)
+
+// Positions are in pixels, texture coordinates are normalized
+type Vertex struct {
+	X     Float
+	Y     Float
+	U     Float
+	V     Float
+	Color Color
+}
+
+// Decorations of text drawn from distance fields.
+// Widths are in pixels on screen, 0 turns them off.
+type TextEffect struct {
+	Outline      Float
+	OutlineColor Color
+	// fades out over its width around the outline
+	Glow      Float
+	GlowColor Color
+}
+
+type DrawCommand struct {
+	Kind    CommandKind
+	Texture TextureId
+	// only set for ClipCommand
+	Clip Quad
+	// only set for SDFTextCommand
+	Effect TextEffect
+	// range within DrawList.Indices
+	IndexOffset int
+	IndexCount  int
+}
+
+// Everything a frame draws, in submission order.
+// Widgets record into it during layout and the
+// backend consumes it in FinishFrame.
+type DrawList struct {
+	Commands []DrawCommand
+	Vertices []Vertex
+	Indices  []uint32
+
+	atlas     *Atlas
+	clipStack []Quad
+}
+
+func NewDrawList(atlas *Atlas) *DrawList {
+	return &DrawList{
+		atlas: atlas,
+	}
+}
+
+// Clears the recorded commands, but keeps the buffers
+// so they can be reused for the next frame
+func (dl *DrawList) Reset() {
+	dl.Commands = dl.Commands[:0]
+	dl.Vertices = dl.Vertices[:0]
+	dl.Indices = dl.Indices[:0]
+	dl.clipStack = dl.clipStack[:0]
+}
+
+func (dl *DrawList) beginCommand(kind CommandKind, tex TextureId) {
+	dl.Commands = append(dl.Commands, DrawCommand{
+		Kind:        kind,
+		Texture:     tex,
+		IndexOffset: len(dl.Indices),
+	})
+}
+
+// Adds two triangles to the current command
+func (dl *DrawList) addQuad(pos Quad, uv Quad, color Color) {
+	i := uint32(len(dl.Vertices))
+
+	dl.Vertices = append(dl.Vertices,
+		Vertex{pos.X, pos.Y, uv.X, uv.Y, color},
+		Vertex{pos.X + pos.W, pos.Y, uv.X + uv.W, uv.Y, color},
+		Vertex{pos.X, pos.Y + pos.H, uv.X, uv.Y + uv.H, color},
+		Vertex{pos.X + pos.W, pos.Y + pos.H, uv.X + uv.W, uv.Y + uv.H, color},
+	)
+
+	dl.Indices = append(dl.Indices,
+		i, i+1, i+2,
+		i+2, i+1, i+3,
+	)
+
+	dl.Commands[len(dl.Commands)-1].IndexCount += 6
+}
+
+func (dl *DrawList) AddRect(pos Quad, color Color) {
+	dl.beginCommand(RectCommand, TextureId{AtlasTexture, 0})
+	dl.addQuad(pos, dl.atlas.WhiteUV(), color)
+}
+
+func (dl *DrawList) AddImage(tex TextureId, pos Quad, uv Quad, tint Color) {
+	dl.beginCommand(ImageCommand, tex)
+	dl.addQuad(pos, uv, tint)
+}
+
+// Rasterizes missing glyphs into the atlas and
+// adds a quad per glyph. Glyphs found in the atlas
+// are not rasterized again. A new command is started,
+// whenever a glyph lives on another atlas page.
+func (dl *DrawList) AddText(placement RenderTextResult, pos Quad, color Color) {
+	dl.addGlyphs(placement, pos, func(Glyph) Color { return color })
+}
+
+func (dl *DrawList) addGlyphs(placement RenderTextResult, pos Quad, colorOf func(Glyph) Color) {
+	page := -1
+
+	for _, p := range placement.PlacedSegments {
+		xadv := p.XOffset
+
+		for _, g := range p.Segment.Glyphs {
+			x := xadv
+			xadv += g.XAdvance
+
+			key := GlyphKey{Font: g.Font.Id, Size: g.Font.Size, GID: g.GID}
+			slot, ok := dl.atlas.Lookup(key)
+			if !ok {
+				var err error
+				if slot, err = dl.rasterize(key, g.Font); err != nil {
+					fmt.Printf("skipping glyph %d: %v\n", g.GID, err)
+					continue
+				}
+			}
+
+			// e.g. spaces, nothing to draw
+			if slot.IsEmpty() {
+				continue
+			}
+
+			glyph := PlaceGlyph(x, p.YOffset+p.Baseline, g, slot.Bounds)
+			glyph.X += pos.X
+			glyph.Y += pos.Y
+
+			if slot.Page != page {
+				page = slot.Page
+				dl.beginCommand(TextCommand, TextureId{AtlasTexture, page})
+			}
+
+			dl.addQuad(glyph, dl.atlas.UV(slot.Rect), colorOf(g))
+		}
+	}
+}
+
+// Draws text placed with PlaceRichText. Every glyph takes the
+// color of its span, highlighted spans are drawn on their
+// background and lines are drawn through or below the others.
+func (dl *DrawList) AddRichText(placement RenderTextResult, text RichText, pos Quad) {
+	EachSpanRun(placement, func(r SpanRun) {
+		if bg := text[r.Span].Background; bg != 0 {
+			b := r.Bounds()
+			dl.AddRect(NewQuad(pos.X+b.X, pos.Y+b.Y, b.W, b.H), bg)
+		}
+	})
+
+	dl.addGlyphs(placement, pos, func(g Glyph) Color {
+		return text[g.Span].Color
+	})
+
+	EachSpanRun(placement, func(r SpanRun) {
+		s := text[r.Span]
+		m := r.Font.Metrics
+		x := pos.X + r.X0
+		w := r.X1 - r.X0
+		baseline := pos.Y + r.Placed.YOffset + r.Placed.Baseline
+
+		if s.Underline {
+			h := max(m.UnderlineThickness, 1)
+			dl.AddRect(NewQuad(x, baseline+m.UnderlinePosition, w, h), s.Color)
+		}
+
+		if s.Strikethrough {
+			h := max(m.StrikethroughThickness, 1)
+			dl.AddRect(NewQuad(x, baseline-m.StrikethroughPosition, w, h), s.Color)
+		}
+	})
+}
+
+// Rasterizes the glyph into a new slot of the atlas
+func (dl *DrawList) rasterize(key GlyphKey, font *FontRepoEntry) (Slot, error) {
+	face, err := font.Face()
+	if err != nil {
+		return Slot{}, err
+	}
+
+	rasterized, metrics, err := GetGlyphBitmap(key.GID, face)
+	if err != nil {
+		return Slot{}, err
+	}
+
+	slot, err := dl.atlas.Reserve(key, GlyphBounds(metrics))
+	if err != nil {
+		return Slot{}, err
+	}
+
+	if !slot.IsEmpty() {
+		dl.atlas.Insert(slot, rasterized)
+	}
+
+	return slot, nil
+}
+
+// Same as AddText, but draws the glyphs from distance fields,
+// which stay crisp at any size and can be outlined or glow.
+// The fields are rasterized once for all sizes of a font.
+func (dl *DrawList) AddTextSDF(placement RenderTextResult, pos Quad, color Color, effect TextEffect) {
+	page := -1
+
+	for _, p := range placement.PlacedSegments {
+		xadv := p.XOffset
+
+		for _, g := range p.Segment.Glyphs {
+			x := xadv
+			xadv += g.XAdvance
+
+			key := GlyphKey{Font: g.Font.Id, Size: SDF_SIZE, GID: g.GID, SDF: true}
+			slot, ok := dl.atlas.Lookup(key)
+			if !ok {
+				var err error
+				if slot, err = dl.rasterizeSDF(key, g.Font); err != nil {
+					fmt.Printf("skipping glyph %d: %v\n", g.GID, err)
+					continue
+				}
+			}
+
+			if slot.IsEmpty() {
+				continue
+			}
+
+			// the field is scaled from SDF_SIZE to the size of the font
+			scale := g.Font.Size / SDF_SIZE
+			b := slot.Bounds
+			glyph := NewQuad(
+				pos.X+x+g.XOffset+Float(b.Min.X)*scale,
+				pos.Y+p.YOffset+p.Baseline-g.YOffset+Float(b.Min.Y)*scale,
+				Float(b.Dx())*scale,
+				Float(b.Dy())*scale,
+			)
+
+			if slot.Page != page {
+				page = slot.Page
+				dl.beginCommand(SDFTextCommand, TextureId{AtlasTexture, page})
+				dl.Commands[len(dl.Commands)-1].Effect = effect
+			}
+
+			dl.addQuad(glyph, dl.atlas.UV(slot.Rect), color)
+		}
+	}
+}
+
+// Rasterizes the glyph at SDF_SIZE and puts its distance field
+// into a new slot of the atlas
+func (dl *DrawList) rasterizeSDF(key GlyphKey, font *FontRepoEntry) (Slot, error) {
+	face, err := font.WithSize(SDF_SIZE).Face()
+	if err != nil {
+		return Slot{}, err
+	}
+
+	rasterized, metrics, err := GetGlyphBitmap(key.GID, face)
+	if err != nil {
+		return Slot{}, err
+	}
+
+	bounds := GlyphBounds(metrics)
+	if bounds.Empty() {
+		return dl.atlas.Reserve(key, bounds)
+	}
+
+	slot, err := dl.atlas.Reserve(key, bounds.Inset(-SDF_SPREAD))
+	if err != nil {
+		return Slot{}, err
+	}
+
+	dl.atlas.Insert(slot, DistanceField(rasterized, SDF_SPREAD))
+	return slot, nil
+}
+
+// Restricts following commands to the given rect,
+// within the clip, which was pushed before
+func (dl *DrawList) PushClip(clip Quad) {
+	clip = clip.Intersect(dl.Clip())
+	dl.clipStack = append(dl.clipStack, clip)
+	dl.addClip(clip)
+}
+
+// The rect commands are currently restricted to,
+// NO_CLIP, if nothing was pushed
+func (dl *DrawList) Clip() Quad {
+	if len(dl.clipStack) == 0 {
+		return NO_CLIP
+	}
+	return dl.clipStack[len(dl.clipStack)-1]
+}
+
+func (dl *DrawList) PopClip() {
+	if len(dl.clipStack) == 0 {
+		panic("PopClip called without matching PushClip")
+	}
+
+	dl.clipStack = dl.clipStack[:len(dl.clipStack)-1]
+	dl.addClip(dl.Clip())
+}
+
+func (dl *DrawList) addClip(clip Quad) {
+	dl.Commands = append(dl.Commands, DrawCommand{
+		Kind:        ClipCommand,
+		Clip:        clip,
+		IndexOffset: len(dl.Indices),
+	})
+}
+
+func (dl *DrawList) Triangles(cmd DrawCommand) []uint32 {
+	return dl.Indices[cmd.IndexOffset : cmd.IndexOffset+cmd.IndexCount]
+}
+
+// Smallest rect containing all vertices of the command
+func (dl *DrawList) Bounds(cmd DrawCommand) Quad {
+	minX, minY := float32(math.MaxFloat32), float32(math.MaxFloat32)
+	maxX, maxY := -minX, -minY
+
+	for _, i := range dl.Triangles(cmd) {
+		v := dl.Vertices[i]
+		minX = min(minX, v.X)
+		minY = min(minY, v.Y)
+		maxX = max(maxX, v.X)
+		maxY = max(maxY, v.Y)
+	}
+
+	if minX > maxX {
+		return Quad{}
+	}
+
+	return NewQuad(minX, minY, maxX-minX, maxY-minY)
+}
+
+var commandNames = []string{"rect", "text", "image", "clip", "sdf text"}
+
+// Human readable listing of the commands,
+// meant for inspecting and diffing frames
+func (dl *DrawList) String() string {
+	var sb strings.Builder
+
+	for _, cmd := range dl.Commands {
+		sb.WriteString(commandNames[cmd.Kind])
+
+		if cmd.Kind == ClipCommand {
+			fmt.Fprintf(&sb, " %v\n", cmd.Clip)
+			continue
+		}
+
+		fmt.Fprintf(
+			&sb,
+			" tex=%v indices=%d..%d bounds=%v\n",
+			cmd.Texture,
+			cmd.IndexOffset,
+			cmd.IndexOffset+cmd.IndexCount,
+			dl.Bounds(cmd),
+		)
+	}
+
+	return sb.String()
+}
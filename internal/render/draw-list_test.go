@@ -0,0 +1,140 @@
+package render
+
+import (
+	. "dyiui/internal/atlas"
+	. "dyiui/internal/text"
+	. "dyiui/internal/types"
+	"path/filepath"
+	"testing"
+)
+
+func NewTestDrawList() *DrawList {
+	var repo AtlasRepo
+	return NewDrawList(repo.Add(0, 256))
+}
+
+func TestRectRecordsTwoTriangles(t *testing.T) {
+	dl := NewTestDrawList()
+	dl.AddRect(NewQuad(10, 20, 30, 40), 0xff0000ff)
+
+	if len(dl.Commands) != 1 || dl.Commands[0].Kind != RectCommand {
+		t.Fatalf("Expected a single rect command, got: %v", dl.Commands)
+	}
+
+	if len(dl.Vertices) != 4 || len(dl.Indices) != 6 {
+		t.Fatalf("Expected 4 vertices and 6 indices, got %d and %d", len(dl.Vertices), len(dl.Indices))
+	}
+
+	b := dl.Bounds(dl.Commands[0])
+	if b != NewQuad(10, 20, 30, 40) {
+		t.Fatalf("Expected bounds to match the rect, got: %v", b)
+	}
+}
+
+func TestClipIsRestoredOnPop(t *testing.T) {
+	dl := NewTestDrawList()
+	outer := NewQuad(0, 0, 100, 100)
+	inner := NewQuad(10, 10, 20, 20)
+
+	dl.PushClip(outer)
+	dl.PushClip(inner)
+	dl.AddRect(NewQuad(0, 0, 5, 5), 0xffffffff)
+	dl.PopClip()
+	dl.PopClip()
+
+	var clips []Quad
+	for _, cmd := range dl.Commands {
+		if cmd.Kind == ClipCommand {
+			clips = append(clips, cmd.Clip)
+		}
+	}
+
+	expected := []Quad{outer, inner, outer, NO_CLIP}
+	if len(clips) != len(expected) {
+		t.Fatalf("Expected %d clip commands, got %d", len(expected), len(clips))
+	}
+
+	for i := range expected {
+		if clips[i] != expected[i] {
+			t.Fatalf("Clip %d should be %v, was %v", i, expected[i], clips[i])
+		}
+	}
+}
+
+func TestResetKeepsNothing(t *testing.T) {
+	dl := NewTestDrawList()
+	dl.AddRect(NewQuad(0, 0, 1, 1), 0xffffffff)
+	dl.Reset()
+
+	if len(dl.Commands) != 0 || len(dl.Vertices) != 0 || len(dl.Indices) != 0 {
+		t.Fatalf("Expected reset draw list to be empty")
+	}
+}
+
+func TestAtlasCommandsShareABatch(t *testing.T) {
+	dl := NewTestDrawList()
+	dl.AddRect(NewQuad(0, 0, 1, 1), 0xffffffff)
+	dl.AddRect(NewQuad(1, 1, 1, 1), 0xffffffff)
+	dl.AddRect(NewQuad(2, 2, 1, 1), 0xffffffff)
+
+	batches := dl.Batches()
+	if len(batches) != 1 || batches[0].IndexCount != 18 {
+		t.Fatalf("Expected a single batch of 18 indices, got: %v", batches)
+	}
+}
+
+func TestTextureAndClipChangesSplitBatches(t *testing.T) {
+	dl := NewTestDrawList()
+	image := TextureId{ImageTexture, 0}
+
+	dl.AddRect(NewQuad(0, 0, 1, 1), 0xffffffff)
+	dl.AddImage(image, NewQuad(0, 0, 1, 1), NewQuad(0, 0, 1, 1), 0xffffffff)
+	dl.AddImage(image, NewQuad(1, 1, 1, 1), NewQuad(0, 0, 1, 1), 0xffffffff)
+	dl.PushClip(NewQuad(0, 0, 10, 10))
+	dl.AddRect(NewQuad(0, 0, 1, 1), 0xffffffff)
+	dl.PopClip()
+
+	batches := dl.Batches()
+	if len(batches) != 3 {
+		t.Fatalf("Expected rect, images and clipped rect as separate batches, got: %v", batches)
+	}
+
+	if batches[1].Texture != image || batches[1].IndexCount != 12 {
+		t.Fatalf("Expected both images to be merged, got: %v", batches[1])
+	}
+
+	if batches[2].Clip != NewQuad(0, 0, 10, 10) {
+		t.Fatalf("Expected last batch to be clipped, got: %v", batches[2])
+	}
+}
+
+func TestCachedGlyphsAreNotRasterizedAgain(t *testing.T) {
+	var fonts FontRepo
+	if _, err := fonts.Load(filepath.Join("..", "..", "testdata", "fonts", "Go-Regular.ttf")); err != nil {
+		t.Fatal(err)
+	}
+
+	dl := NewTestDrawList()
+	placement := PlaceSegments("a b a", fonts.Get(), 1000, TextLayoutOptions{})
+
+	dl.AddText(placement, NewQuad(0, 0, 0, 0), 0xffffffff)
+	for _, p := range dl.atlas.Pages {
+		p.Dirty = false
+	}
+
+	stats := dl.atlas.Cache.Stats
+	dl.AddText(placement, NewQuad(0, 0, 0, 0), 0xffffffff)
+
+	if dl.atlas.Cache.Stats.Misses != stats.Misses {
+		t.Fatalf("Expected only atlas hits for known glyphs, got %+v", dl.atlas.Cache.Stats)
+	}
+
+	if dl.atlas.Pages[0].Dirty {
+		t.Fatalf("Expected the atlas to stay untouched")
+	}
+
+	// both a, both spaces and b, including the empty space glyph
+	if stats.Misses != 3 {
+		t.Fatalf("Expected a miss per distinct glyph, got %+v", stats)
+	}
+}
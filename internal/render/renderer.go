@@ -0,0 +1,24 @@
+package render
+
+import (
+	. "dyiui/internal/atlas"
+	. "dyiui/internal/text"
+	"image"
+)
+
+// Implemented by the OpenGL backend (internal/gl)
+// and the software backend (internal/soft).
+//
+// Widgets don't draw through the backend directly,
+// but record into a DrawList, which the backend
+// consumes at the end of the frame.
+type Renderer interface {
+	BeginFrame()
+	FinishFrame(list *DrawList)
+
+	GetFonts() *FontRepo
+	GetAtlas() *Atlas
+
+	// Makes the image available to ImageCommands
+	UploadImage(img image.Image) TextureId
+}
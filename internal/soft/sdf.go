@@ -0,0 +1,111 @@
+package soft
+
+import (
+	. "dyiui/internal/atlas"
+	. "dyiui/internal/color"
+	"dyiui/internal/render"
+	. "dyiui/internal/types"
+	"image"
+	"math"
+)
+
+// Alpha of the atlas at the texture coordinates,
+// interpolated between the four nearest texels.
+// Distance fields need this, their outlines run
+// between the texels.
+func sampleBilinear(tex *image.NRGBA, u, v Float) Float {
+	w := tex.Rect.Dx()
+	h := tex.Rect.Dy()
+
+	x := u*Float(w) - .5
+	y := v*Float(h) - .5
+
+	x0 := int(math.Floor(float64(x)))
+	y0 := int(math.Floor(float64(y)))
+	fx := x - Float(x0)
+	fy := y - Float(y0)
+
+	at := func(x, y int) Float {
+		x = min(max(x, 0), w-1)
+		y = min(max(y, 0), h-1)
+		return Float(tex.NRGBAAt(x, y).A) / 255.0
+	}
+
+	top := at(x0, y0)*(1-fx) + at(x0+1, y0)*fx
+	bottom := at(x0, y0+1)*(1-fx) + at(x0+1, y0+1)*fx
+
+	return top*(1-fy) + bottom*fy
+}
+
+func clamp01(v Float) Float {
+	return min(max(v, 0), 1)
+}
+
+// Composites a color with straight alpha over another one
+func over(top, bottom [4]Float) [4]Float {
+	a := top[3] + bottom[3]*(1-top[3])
+	if a == 0 {
+		return [4]Float{}
+	}
+
+	var c [4]Float
+	for i := 0; i < 3; i++ {
+		c[i] = (top[i]*top[3] + bottom[i]*bottom[3]*(1-top[3])) / a
+	}
+	c[3] = a
+
+	return c
+}
+
+func withAlpha(c [4]Float, coverage Float) [4]Float {
+	c[3] *= coverage
+	return c
+}
+
+// Shades a pixel of text drawn from a distance field, the same way
+// the fragment shader of the OpenGL backend does. d is the sampled
+// field, g how much it changes from one pixel on screen to the next.
+func sdfColor(d, g Float, color [4]Float, effect render.TextEffect) [4]Float {
+	// distance to the outline in pixels, positive inside
+	px := (d - .5) / g
+
+	c := withAlpha(color, clamp01(px+.5))
+
+	if effect.Outline > 0 {
+		outline := withAlpha(ColorToGlVec4(effect.OutlineColor), clamp01(px+effect.Outline+.5))
+		c = over(c, outline)
+	}
+
+	if effect.Glow > 0 {
+		fade := 1 - clamp01(-(px+effect.Outline)/effect.Glow)
+		glow := withAlpha(ColorToGlVec4(effect.GlowColor), fade*fade)
+		c = over(c, glow)
+	}
+
+	return c
+}
+
+// Pixels on screen, one texel of the field at SDF_SIZE covers,
+// derived from how the texture coordinates change across the triangle
+func sdfGradient(a, b, c render.Vertex, area Float, tex *image.NRGBA) Float {
+	// derivatives of the barycentric weights
+	dl0x, dl0y := (b.Y-c.Y)/area, (c.X-b.X)/area
+	dl1x, dl1y := (c.Y-a.Y)/area, (a.X-c.X)/area
+	dl2x, dl2y := (a.Y-b.Y)/area, (b.X-a.X)/area
+
+	w := Float(tex.Rect.Dx())
+	h := Float(tex.Rect.Dy())
+
+	dudx := (dl0x*a.U + dl1x*b.U + dl2x*c.U) * w
+	dvdx := (dl0x*a.V + dl1x*b.V + dl2x*c.V) * h
+	dudy := (dl0y*a.U + dl1y*b.U + dl2y*c.U) * w
+	dvdy := (dl0y*a.V + dl1y*b.V + dl2y*c.V) * h
+
+	texels := (length(dudx, dvdx) + length(dudy, dvdy)) / 2
+
+	return texels / (2 * SDF_SPREAD)
+}
+
+func length(x, y Float) Float {
+	return Float(math.Sqrt(float64(x*x + y*y)))
+}
@@ -0,0 +1,252 @@
+package soft
+
+import (
+	. "dyiui/internal/atlas"
+	. "dyiui/internal/color"
+	"dyiui/internal/render"
+	. "dyiui/internal/text"
+	. "dyiui/internal/types"
+	"fmt"
+	"image"
+	"image/draw"
+	"math"
+)
+
+const CLEAR_COLOR Color = 0x000000ff
+
+const ATLAS_SIZE = 1024
+
+// Software backend, which rasterizes the draw list into
+// an image instead of issuing OpenGL calls. Used to render
+// frames on machines without a GPU.
+type Renderer struct {
+	Width  int
+	Height int
+	Fonts  FontRepo
+	Target *image.RGBA
+
+	atlases AtlasRepo
+	images  []*image.NRGBA
+}
+
+func NewRenderer(width, height int, fontPath string) *Renderer {
+	r := Renderer{
+		Width:  width,
+		Height: height,
+		Fonts:  NewFontRepo(),
+		Target: image.NewRGBA(image.Rect(0, 0, width, height)),
+	}
+
+	fmt.Printf("loading font from %s\n", fontPath)
+	if _, err := r.Fonts.Load(fontPath); err != nil {
+		panic(err)
+	}
+
+	return &r
+}
+
+func (r *Renderer) GetFonts() *FontRepo {
+	return &r.Fonts
+}
+
+func (r *Renderer) GetAtlas() *Atlas {
+	var id AtlasId = 0
+	atlas := r.atlases.Get(id)
+
+	if atlas == nil {
+		atlas = r.atlases.Add(id, ATLAS_SIZE)
+	}
+
+	return atlas
+}
+
+func (r *Renderer) UploadImage(img image.Image) render.TextureId {
+	b := img.Bounds()
+	nrgba := image.NewNRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(nrgba, nrgba.Rect, img, b.Min, draw.Src)
+
+	r.images = append(r.images, nrgba)
+
+	return render.TextureId{Kind: render.ImageTexture, Index: len(r.images) - 1}
+}
+
+func (r *Renderer) texture(id render.TextureId) *image.NRGBA {
+	if id.Kind == render.ImageTexture {
+		return r.images[id.Index]
+	}
+
+	return r.GetAtlas().Pages[id.Index].Image
+}
+
+func (r *Renderer) BeginFrame() {
+	r.Fonts.Layouts.NextFrame()
+
+	c := ColorToGlVec4(CLEAR_COLOR)
+	for i := 0; i < len(r.Target.Pix); i += 4 {
+		for j := 0; j < 4; j++ {
+			r.Target.Pix[i+j] = uint8(c[j] * 255)
+		}
+	}
+}
+
+func (r *Renderer) FinishFrame(list *render.DrawList) {
+	for _, batch := range list.Batches() {
+		clip := r.clipRect(batch.Clip)
+		tex := r.texture(batch.Texture)
+		indices := list.Indices[batch.IndexOffset : batch.IndexOffset+batch.IndexCount]
+
+		var effect *render.TextEffect
+		if batch.SDF {
+			effect = &batch.Effect
+		}
+
+		for i := 0; i+2 < len(indices); i += 3 {
+			r.drawTriangle(
+				list.Vertices[indices[i]],
+				list.Vertices[indices[i+1]],
+				list.Vertices[indices[i+2]],
+				tex,
+				clip,
+				effect,
+			)
+		}
+	}
+
+	// the atlas is sampled directly, nothing to mirror
+	for _, page := range r.GetAtlas().Pages {
+		page.Dirty = false
+	}
+}
+
+// Pixels, whose center lies within the clip
+func (r *Renderer) clipRect(clip Quad) image.Rectangle {
+	if clip == render.NO_CLIP {
+		return r.Target.Rect
+	}
+
+	x0 := max(clip.X, 0)
+	y0 := max(clip.Y, 0)
+	x1 := min(clip.X+clip.W, Float(r.Width))
+	y1 := min(clip.Y+clip.H, Float(r.Height))
+
+	return image.Rect(
+		int(math.Round(float64(x0))),
+		int(math.Round(float64(y0))),
+		int(math.Round(float64(x1))),
+		int(math.Round(float64(y1))),
+	).Intersect(r.Target.Rect)
+}
+
+func edge(a, b render.Vertex, x, y Float) Float {
+	return (b.X-a.X)*(y-a.Y) - (b.Y-a.Y)*(x-a.X)
+}
+
+// Decides which of two triangles sharing an edge owns the pixels
+// lying exactly on it, so they are not blended twice. Both traverse
+// the edge in opposite directions, only one of them passes.
+func owns(a, b render.Vertex) bool {
+	dx := b.X - a.X
+	dy := b.Y - a.Y
+	return dy > 0 || (dy == 0 && dx > 0)
+}
+
+// Rasterizes the triangle by sampling at pixel centers,
+// with the same blending the OpenGL backend configures:
+// src * src_alpha + dst * (1 - src_alpha).
+// Text drawn from distance fields is shaded with the effect.
+func (r *Renderer) drawTriangle(a, b, c render.Vertex, tex *image.NRGBA, clip image.Rectangle, effect *render.TextEffect) {
+	area := edge(a, b, c.X, c.Y)
+	if area == 0 {
+		return
+	}
+
+	if area < 0 {
+		b, c = c, b
+		area = -area
+	}
+
+	bounds := image.Rect(
+		int(math.Floor(float64(min(a.X, b.X, c.X)))),
+		int(math.Floor(float64(min(a.Y, b.Y, c.Y)))),
+		int(math.Ceil(float64(max(a.X, b.X, c.X)))),
+		int(math.Ceil(float64(max(a.Y, b.Y, c.Y)))),
+	).Intersect(clip)
+
+	ca := ColorToGlVec4(a.Color)
+	cb := ColorToGlVec4(b.Color)
+	cc := ColorToGlVec4(c.Color)
+
+	texW := tex.Rect.Dx()
+	texH := tex.Rect.Dy()
+
+	var g Float
+	if effect != nil {
+		g = sdfGradient(a, b, c, area, tex)
+	}
+
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			px := Float(x) + .5
+			py := Float(y) + .5
+
+			w0 := edge(b, c, px, py)
+			w1 := edge(c, a, px, py)
+			w2 := edge(a, b, px, py)
+
+			if w0 < 0 || w1 < 0 || w2 < 0 {
+				continue
+			}
+			if (w0 == 0 && !owns(b, c)) || (w1 == 0 && !owns(c, a)) || (w2 == 0 && !owns(a, b)) {
+				continue
+			}
+
+			l0 := w0 / area
+			l1 := w1 / area
+			l2 := w2 / area
+
+			u := l0*a.U + l1*b.U + l2*c.U
+			v := l0*a.V + l1*b.V + l2*c.V
+
+			if effect != nil {
+				var color [4]Float
+				for i := range color {
+					color[i] = l0*ca[i] + l1*cb[i] + l2*cc[i]
+				}
+
+				r.blend(x, y, sdfColor(sampleBilinear(tex, u, v), g, color, *effect))
+				continue
+			}
+
+			tx := min(max(int(u*Float(texW)), 0), texW-1)
+			ty := min(max(int(v*Float(texH)), 0), texH-1)
+			texel := tex.NRGBAAt(tx, ty)
+			t := [4]Float{
+				Float(texel.R) / 255.0,
+				Float(texel.G) / 255.0,
+				Float(texel.B) / 255.0,
+				Float(texel.A) / 255.0,
+			}
+
+			var src [4]Float
+			for i := range src {
+				src[i] = (l0*ca[i] + l1*cb[i] + l2*cc[i]) * t[i]
+			}
+
+			r.blend(x, y, src)
+		}
+	}
+}
+
+func (r *Renderer) blend(x, y int, src [4]Float) {
+	i := r.Target.PixOffset(x, y)
+	dst := r.Target.Pix[i : i+4]
+	alpha := src[3]
+
+	for j := 0; j < 3; j++ {
+		d := Float(dst[j]) / 255.0
+		dst[j] = uint8(math.Round(float64((src[j]*alpha + d*(1-alpha)) * 255.0)))
+	}
+
+	d := Float(dst[3]) / 255.0
+	dst[3] = uint8(math.Round(float64((alpha + d*(1-alpha)) * 255.0)))
+}
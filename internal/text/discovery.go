@@ -0,0 +1,349 @@
+package text
+
+import (
+	"encoding/binary"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/benoitkugler/textlayout/fonts/truetype"
+)
+
+// Additional directories to search for fonts,
+// separated like PATH
+const FONT_PATH_ENV = "DYIUI_FONT_PATH"
+
+// Families tried by GetSomeFont, in order
+var DEFAULT_FAMILIES = []string{
+	"Noto Sans",
+	"DejaVu Sans",
+	"Ubuntu",
+	"Liberation Sans",
+	"Roboto",
+	"Segoe UI",
+	"Helvetica",
+	"Arial",
+	"Go",
+}
+
+var fontExtensions = map[string]bool{
+	".ttf": true,
+	".otf": true,
+	".ttc": true,
+	".otc": true,
+}
+
+// A font found while scanning
+type FontFile struct {
+	Path string
+	// of the font within a collection, 0 for plain font files
+	Index int
+	FontDescriptor
+	// subfamily as named by the font, e.g. "Bold Italic"
+	Style string
+}
+
+// Fonts on disk by family. Only the name and OS/2 tables
+// are read while scanning, fonts are parsed, when loaded.
+type FontIndex struct {
+	Files []FontFile
+	// lower case family to positions in Files
+	families map[string][]int
+}
+
+func NewFontIndex() *FontIndex {
+	return &FontIndex{
+		families: make(map[string][]int),
+	}
+}
+
+// Directories fonts are usually installed to,
+// followed by the ones configured with FONT_PATH_ENV
+func FontDirs() []string {
+	var dirs []string
+	home, _ := os.UserHomeDir()
+
+	switch runtime.GOOS {
+	case "windows":
+		dirs = append(dirs, filepath.Join(os.Getenv("WINDIR"), "Fonts"))
+		if local := os.Getenv("LOCALAPPDATA"); local != "" {
+			dirs = append(dirs, filepath.Join(local, "Microsoft", "Windows", "Fonts"))
+		}
+	case "darwin":
+		dirs = append(dirs, "/System/Library/Fonts", "/Library/Fonts")
+		if home != "" {
+			dirs = append(dirs, filepath.Join(home, "Library", "Fonts"))
+		}
+	default:
+		dirs = append(dirs, "/usr/share/fonts", "/usr/local/share/fonts")
+		if home != "" {
+			dirs = append(dirs, filepath.Join(home, ".fonts"), filepath.Join(home, ".local", "share", "fonts"))
+		}
+	}
+
+	for _, dir := range filepath.SplitList(os.Getenv(FONT_PATH_ENV)) {
+		if dir != "" {
+			dirs = append(dirs, dir)
+		}
+	}
+
+	return dirs
+}
+
+var systemFonts *FontIndex
+var systemFontsOnce sync.Once
+
+// Index of the fonts in FontDirs, scanned on first use
+func SystemFonts() *FontIndex {
+	systemFontsOnce.Do(func() {
+		systemFonts = NewFontIndex()
+		systemFonts.ScanDirs(FontDirs()...)
+	})
+
+	return systemFonts
+}
+
+// Adds the fonts within the directories and their subdirectories.
+// Missing directories and files, which are no fonts, are skipped.
+func (idx *FontIndex) ScanDirs(dirs ...string) {
+	for _, dir := range dirs {
+		filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				// unreadable directories are skipped, not the whole scan
+				return nil
+			}
+
+			if !d.IsDir() && fontExtensions[strings.ToLower(filepath.Ext(path))] {
+				idx.AddFile(path)
+			}
+
+			return nil
+		})
+	}
+}
+
+// Adds all fonts of the file, collections contain several.
+// Tables are read from the file as they are needed.
+func (idx *FontIndex) AddFile(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	parsers, err := truetype.NewFontParsers(file)
+	if err != nil {
+		return err
+	}
+
+	for i, pr := range parsers {
+		raw, err := pr.GetRawTable(truetype.MustNewTag("name"))
+		if err != nil {
+			continue
+		}
+
+		names, err := parseNames(raw)
+		if err != nil {
+			continue
+		}
+
+		os2, _ := pr.OS2Table()
+
+		d, style := describe(names, os2)
+		if d.Family == "" {
+			continue
+		}
+
+		idx.add(FontFile{
+			Path:           path,
+			Index:          i,
+			FontDescriptor: d,
+			Style:          style,
+		})
+	}
+
+	return nil
+}
+
+func (idx *FontIndex) add(f FontFile) {
+	key := strings.ToLower(f.Family)
+	idx.families[key] = append(idx.families[key], len(idx.Files))
+	idx.Files = append(idx.Files, f)
+}
+
+// Known families, sorted
+func (idx *FontIndex) Families() []string {
+	var families []string
+	for _, positions := range idx.families {
+		families = append(families, idx.Files[positions[0]].Family)
+	}
+
+	sort.Strings(families)
+	return families
+}
+
+// Font of the family, which is closest in style
+func (idx *FontIndex) Find(d FontDescriptor) (FontFile, bool) {
+	var best FontFile
+	bestDistance := -1
+
+	for _, i := range idx.families[strings.ToLower(d.Family)] {
+		f := idx.Files[i]
+		distance := styleDistance(f.FontDescriptor, d)
+
+		if bestDistance < 0 || distance < bestDistance {
+			best = f
+			bestDistance = distance
+		}
+	}
+
+	return best, bestDistance >= 0
+}
+
+// Finds a font by a name like "Noto Sans Bold Italic", which is a
+// family followed by style words. The longest known family wins,
+// so "Noto Sans Mono" is not mistaken for "Noto Sans" in Mono.
+func (idx *FontIndex) Resolve(name string) (FontFile, bool) {
+	words := strings.Fields(name)
+
+	for n := len(words); n > 0; n-- {
+		family := strings.Join(words[:n], " ")
+		if _, ok := idx.families[strings.ToLower(family)]; !ok {
+			continue
+		}
+
+		d, ok := parseStyle(words[n:])
+		if !ok {
+			continue
+		}
+
+		d.Family = family
+		return idx.Find(d)
+	}
+
+	return FontFile{}, false
+}
+
+var weightNames = map[string]Weight{
+	"thin":       WeightThin,
+	"hairline":   WeightThin,
+	"extralight": 200,
+	"ultralight": 200,
+	"light":      WeightLight,
+	"regular":    WeightRegular,
+	"normal":     WeightRegular,
+	"book":       WeightRegular,
+	"medium":     WeightMedium,
+	"semibold":   600,
+	"demibold":   600,
+	"bold":       WeightBold,
+	"extrabold":  800,
+	"ultrabold":  800,
+	"black":      WeightBlack,
+	"heavy":      WeightBlack,
+}
+
+// Weight and slant from words like "Semi Bold Italic",
+// fails on words, which describe no style
+func parseStyle(words []string) (FontDescriptor, bool) {
+	d := FontDescriptor{Weight: WeightRegular}
+
+	for i := 0; i < len(words); i++ {
+		w := strings.ToLower(words[i])
+
+		// "Semi Bold", "Extra Light" and the like
+		if i+1 < len(words) {
+			if weight, ok := weightNames[w+strings.ToLower(words[i+1])]; ok {
+				d.Weight = weight
+				i += 1
+				continue
+			}
+		}
+
+		if weight, ok := weightNames[w]; ok {
+			d.Weight = weight
+		} else if w == "italic" || w == "oblique" {
+			d.Italic = true
+		} else {
+			return d, false
+		}
+	}
+
+	return d, true
+}
+
+// Descriptor and subfamily from the name table, the OS/2 table
+// takes precedence for weight and slant, if the font has one
+func describe(names truetype.TableName, os2 *truetype.TableOS2) (FontDescriptor, string) {
+	family := nameOf(names, truetype.NamePreferredFamily)
+	if family == "" {
+		family = nameOf(names, truetype.NameFontFamily)
+	}
+
+	style := nameOf(names, truetype.NamePreferredSubfamily)
+	if style == "" {
+		style = nameOf(names, truetype.NameFontSubfamily)
+	}
+
+	d, _ := parseStyle(strings.Fields(style))
+	d.Family = family
+
+	if os2 != nil {
+		if os2.USWeightClass != 0 {
+			d.Weight = Weight(os2.USWeightClass)
+		}
+		// italic or oblique bit
+		d.Italic = os2.FsSelection&1 != 0 || os2.FsSelection&(1<<9) != 0
+	}
+
+	return d, style
+}
+
+func nameOf(names truetype.TableName, id truetype.NameID) string {
+	if e := names.SelectEntry(id); e != nil {
+		return strings.TrimSpace(e.String())
+	}
+	return ""
+}
+
+// Parses the records of a raw name table
+func parseNames(buf []byte) (truetype.TableName, error) {
+	if len(buf) < 6 {
+		return nil, errors.New("name table too short")
+	}
+
+	count := int(binary.BigEndian.Uint16(buf[2:]))
+	stringOffset := int(binary.BigEndian.Uint16(buf[4:]))
+
+	if len(buf) < 6+count*12 {
+		return nil, errors.New("name table too short")
+	}
+
+	names := make(truetype.TableName, 0, count)
+
+	for i := 0; i < count; i++ {
+		r := buf[6+i*12:]
+		length := int(binary.BigEndian.Uint16(r[8:]))
+		offset := int(binary.BigEndian.Uint16(r[10:]))
+
+		start := stringOffset + offset
+		if start+length > len(buf) {
+			continue
+		}
+
+		names = append(names, truetype.NameEntry{
+			Value:      buf[start : start+length],
+			PlatformID: truetype.PlatformID(binary.BigEndian.Uint16(r[0:])),
+			EncodingID: truetype.PlatformEncodingID(binary.BigEndian.Uint16(r[2:])),
+			LanguageID: truetype.PlatformLanguageID(binary.BigEndian.Uint16(r[4:])),
+			NameID:     truetype.NameID(binary.BigEndian.Uint16(r[6:])),
+		})
+	}
+
+	return names, nil
+}
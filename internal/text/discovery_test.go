@@ -0,0 +1,90 @@
+package text
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func ScanTestFonts() *FontIndex {
+	idx := NewFontIndex()
+	idx.ScanDirs(testFontsDir())
+	return idx
+}
+
+func TestScanFindsFamilies(t *testing.T) {
+	idx := ScanTestFonts()
+
+	families := idx.Families()
+	if len(families) != 2 || families[0] != "Go" || families[1] != "cmapTest" {
+		t.Fatalf("Expected the families of the fixture fonts, got %v", families)
+	}
+
+	if len(idx.Files) != 3 {
+		t.Fatalf("Expected a file per fixture font, got %d", len(idx.Files))
+	}
+}
+
+func TestResolveFamilyAndStyle(t *testing.T) {
+	idx := ScanTestFonts()
+
+	cases := map[string]string{
+		"Go":           "Go-Regular.ttf",
+		"go regular":   "Go-Regular.ttf",
+		"Go Bold":      "Go-Bold.ttf",
+		"Go Semi Bold": "Go-Bold.ttf",
+		"cmapTest":     "cmapTest.ttf",
+	}
+
+	for name, expected := range cases {
+		f, ok := idx.Resolve(name)
+		if !ok {
+			t.Fatalf("Could not resolve '%s'", name)
+		}
+
+		if filepath.Base(f.Path) != expected {
+			t.Fatalf("Expected '%s' to resolve to %s, got %s", name, expected, f.Path)
+		}
+	}
+}
+
+func TestResolveUnknown(t *testing.T) {
+	idx := ScanTestFonts()
+
+	for _, name := range []string{"Noto Sans Bold", "Go Fancy", ""} {
+		if f, ok := idx.Resolve(name); ok {
+			t.Fatalf("Expected '%s' not to resolve, got %s", name, f.Path)
+		}
+	}
+}
+
+func TestParseStyle(t *testing.T) {
+	d, ok := parseStyle([]string{"Extra", "Light", "Italic"})
+	if !ok || d.Weight != 200 || !d.Italic {
+		t.Fatalf("Unexpected style %+v", d)
+	}
+
+	if _, ok := parseStyle([]string{"Mono"}); ok {
+		t.Fatalf("Expected 'Mono' not to be a style")
+	}
+}
+
+func TestFontPathEnvIsSearched(t *testing.T) {
+	t.Setenv(FONT_PATH_ENV, testFontsDir())
+
+	dirs := FontDirs()
+	if dirs[len(dirs)-1] != testFontsDir() {
+		t.Fatalf("Expected configured directory to be searched, got %v", dirs)
+	}
+}
+
+func TestLoadFamilyLoadsAllStyles(t *testing.T) {
+	var repo FontRepo
+	if err := repo.LoadFamily(ScanTestFonts(), "go"); err != nil {
+		t.Fatal(err)
+	}
+
+	bold := repo.Find(FontQuery{FontDescriptor: FontDescriptor{Family: "Go", Weight: WeightBold}})
+	if filepath.Base(bold.Source.Path) != "Go-Bold.ttf" {
+		t.Fatalf("Expected bold style to be loaded, got %s", bold.Source.Path)
+	}
+}
@@ -4,144 +4,476 @@ import (
 	"bytes"
 	"fmt"
 	"os"
+	"strings"
 
 	"dyiui/internal/globals"
 	. "dyiui/internal/units"
 
+	"github.com/benoitkugler/textlayout/fonts"
 	"github.com/benoitkugler/textlayout/fonts/truetype"
 	"github.com/benoitkugler/textlayout/harfbuzz"
 	"github.com/danielgatis/go-freetype/freetype"
 )
 
+type FontId = int
+
+type Weight = int
+
+// Weight classes as used in the OS/2 table
+const (
+    WeightThin Weight = 100
+    WeightLight Weight = 300
+    WeightRegular Weight = 400
+    WeightMedium Weight = 500
+    WeightBold Weight = 700
+    WeightBlack Weight = 900
+)
+
+// Size of the default font in pixels
+const DEFAULT_FONT_SIZE = 32
+
+// Identifies a font within the fonts of a repo
+type FontDescriptor struct {
+    Family string
+    Weight Weight
+    Italic bool
+}
+
+// What is asked for, the closest match is returned
+type FontQuery struct {
+    FontDescriptor
+    // in pixels
+    Size float32
+}
+
+// A loaded font file, shared by the faces of all sizes
+type FontSource struct {
+    Id FontId
+    Descriptor FontDescriptor
+    Path string
+    // of the font within a collection
+    Index int
+    Ttf *truetype.Font
+    HbFont *harfbuzz.Font
+    data []byte
+}
+
+// Vertical metrics of a font in pixels, all of them are
+// positive, the descent is measured below the baseline
+type FontMetrics struct {
+    Ascent float32
+    Descent float32
+    LineGap float32
+    // of the top of the line below the baseline
+    UnderlinePosition float32
+    UnderlineThickness float32
+    // of the top of the line above the baseline
+    StrikethroughPosition float32
+    StrikethroughThickness float32
+}
+
+// Distance between the baselines of two lines
+func (m FontMetrics) LineHeight() float32 {
+    return m.Ascent + m.Descent + m.LineGap
+}
+
+// Distance of the baseline from the top of a line of the given
+// height, the space not taken by the glyphs is split evenly
+func (m FontMetrics) Baseline(lineHeight float32) float32 {
+    return (lineHeight - m.Ascent - m.Descent) / 2 + m.Ascent
+}
+
+// A font at a certain size
 type FontRepoEntry struct {
-    FontFace *freetype.Face
+    // unique within the repo, e.g. to tell glyphs
+    // of different fonts apart in the atlas
+    Id FontId
+    // in pixels
+    Size float32
+    Source *FontSource
     HbFont *harfbuzz.Font
     Ttf *truetype.Font
+    Metrics FontMetrics
+
+    repo *FontRepo
+    // created on first use, as only rasterizing needs it
+    face *freetype.Face
+    // resolved on first use, since most text
+    // never needs anything but the primary font
+    fallbacks []*FontRepoEntry
+    fallbacksResolved bool
+}
+
+type faceKey struct {
+    source *FontSource
+    size float32
 }
 
 type FontRepo struct {
-    entries []FontRepoEntry
+    sources []*FontSource
+    entries map[faceKey]*FontRepoEntry
+
+    // what Get returns, defaults to the first loaded font
+    Default FontQuery
+    // families tried in order, when a font lacks a glyph
+    Fallbacks []string
+    // text shaped with the fonts of the repo, advanced
+    // by the renderers at the start of every frame
+    Layouts LayoutCache
+
+    // shared by the faces of all entries, created with the first one
+    library *freetype.Library
 }
 
 func NewFontRepo() FontRepo {
     return FontRepo{}
 }
 
-func (r FontRepo) Get() *FontRepoEntry {
-    if r.entries == nil || len(r.entries) == 0 {
+// The default font
+func (r *FontRepo) Get() *FontRepoEntry {
+    return r.Find(r.Default)
+}
+
+// Closest match of the query. Fonts of another family are only
+// returned, if the family is unknown, then the default family is used.
+// Returns nil, if no fonts are loaded.
+func (r *FontRepo) Find(q FontQuery) *FontRepoEntry {
+    source := r.match(q.FontDescriptor)
+    if source == nil {
+        source = r.match(FontDescriptor {
+            Family: r.Default.Family,
+            Weight: q.Weight,
+            Italic: q.Italic,
+        })
+    }
+
+    if source == nil {
         return nil
     }
 
-    return &r.entries[0]
+    size := q.Size
+    if size <= 0 {
+        size = DEFAULT_FONT_SIZE
+    }
+
+    return r.entry(source, size)
 }
 
-func LoadTTF(path string) (*truetype.Font, error) {
-	f := GetSomeFont()
+// Font of the family, which is closest in style
+func (r *FontRepo) match(d FontDescriptor) *FontSource {
+    var best *FontSource
+    bestDistance := 0
+
+    for _, s := range r.sources {
+        if !strings.EqualFold(s.Descriptor.Family, d.Family) {
+            continue
+        }
+
+        distance := styleDistance(s.Descriptor, d)
+        if best == nil || distance < bestDistance {
+            best = s
+            bestDistance = distance
+        }
+    }
 
-	file, err := os.Open(f)
-	
-	if err != nil {
-		return nil, err
-	}
+    return best
+}
 
-	font, err := truetype.Parse(file)
+// How far apart two fonts of a family look
+func styleDistance(a, b FontDescriptor) int {
+    distance := a.Weight - b.Weight
+    if distance < 0 {
+        distance = -distance
+    }
 
-	return font, err
+    // a slanted font looks more off than a wrong weight
+    if a.Italic != b.Italic {
+        distance += 1000
+    }
+
+    return distance
 }
 
-func InitFace(px float32, path string) *freetype.Face {
-	data, err := os.ReadFile(path)
+func (r *FontRepo) entry(source *FontSource, size float32) *FontRepoEntry {
+    key := faceKey{ source, size }
 
-	if err != nil {
-		panic(err)
-	}
+    if e := r.entries[key]; e != nil {
+        return e
+    }
 
-	lib, err := freetype.NewLibrary()
-	if err != nil {
-		panic(err)
-	}
+    if r.entries == nil {
+        r.entries = make(map[faceKey]*FontRepoEntry)
+    }
 
-	face, err := freetype.NewFace(lib, data, 0)
-	if err != nil {
-		panic(err)
-	}
+    e := &FontRepoEntry {
+        Id: source.Id,
+        Size: size,
+        Source: source,
+        HbFont: source.HbFont,
+        Ttf: source.Ttf,
+        Metrics: metricsOf(source.Ttf, size),
+        repo: r,
+    }
 
-	pt := int(PxToPt(px))
+    r.entries[key] = e
+    return e
+}
 
-	if globals.LOG_FONT {
-		fmt.Printf("Retrieving font face of size %vpt\n", pt)
-	}
-	
-	err = face.Pt(pt, int(PIXELS_PER_LOGICAL_INCH))
-	if err != nil {
-		panic(err)
-	}
+// Ascender, descender and line gap from the OS/2 or hhea table
+func metricsOf(ttf *truetype.Font, size float32) FontMetrics {
+    extents, ok := ttf.FontHExtents()
+    if !ok {
+        // neither table is present, guess like most fonts look
+        upem := float32(ttf.Upem())
+        extents = fonts.FontExtents{ Ascender: .8 * upem, Descender: -.2 * upem }
+    }
+
+    factor := FontScaleFactor(ttf, GetDefaultMetric(), Sp(size))
 
-	return face
+    m := FontMetrics {
+        Ascent: extents.Ascender * factor,
+        Descent: -extents.Descender * factor,
+        LineGap: extents.LineGap * factor,
+    }
+
+    // guesses for fonts, which do not tell
+    m.UnderlinePosition = m.Descent / 2
+    m.UnderlineThickness = size / 14
+    m.StrikethroughPosition = m.Ascent / 3
+    m.StrikethroughThickness = size / 14
+
+    if thickness, ok := ttf.LineMetric(fonts.UnderlineThickness); ok && thickness > 0 {
+        position, _ := ttf.LineMetric(fonts.UnderlinePosition)
+        m.UnderlinePosition = -position * factor
+        m.UnderlineThickness = thickness * factor
+    }
+
+    if thickness, ok := ttf.LineMetric(fonts.StrikethroughThickness); ok && thickness > 0 {
+        position, _ := ttf.LineMetric(fonts.StrikethroughPosition)
+        m.StrikethroughPosition = position * factor
+        m.StrikethroughThickness = thickness * factor
+    }
+
+    return m
 }
 
-func (r *FontRepo) Load(path string) {
-    face := InitFace(32, path)
-	ttf, err := LoadTTF(path)
-	if err != nil {
-		panic(err)
-	}
+// Freetype face for rasterizing glyphs at the entry's size
+func (e *FontRepoEntry) Face() (*freetype.Face, error) {
+    if e.face != nil {
+        return e.face, nil
+    }
+
+    r := e.repo
+    if r.library == nil {
+        library, err := freetype.NewLibrary()
+        if err != nil {
+            return nil, err
+        }
+        r.library = library
+    }
+
+    face, err := newFace(r.library, e.Source.data, e.Source.Index, e.Size)
+    if err != nil {
+        return nil, fmt.Errorf("face of %s at %vpx: %w", e.Source.Path, e.Size, err)
+    }
+
+    e.face = face
+    return face, nil
+}
+
+// Frees the faces created for rasterizing. The fonts stay
+// loaded, faces are created again, when they are needed.
+func (r *FontRepo) Close() error {
+    var first error
+    keep := func(err error) {
+        if first == nil {
+            first = err
+        }
+    }
+
+    for _, e := range r.entries {
+        if e.face != nil {
+            if err := e.face.Done(); err != nil {
+                keep(err)
+            }
+            e.face = nil
+        }
+    }
+
+    if r.library != nil {
+        if err := r.library.Done(); err != nil {
+            keep(err)
+        }
+        r.library = nil
+    }
+
+    return first
+}
+
+// The same font at another size
+func (e *FontRepoEntry) WithSize(size float32) *FontRepoEntry {
+    if size == e.Size {
+        return e
+    }
+    return e.repo.entry(e.Source, size)
+}
+
+// Fonts of the same size and style to try, when this one lacks a glyph
+func (e *FontRepoEntry) Fallbacks() []*FontRepoEntry {
+    if e.fallbacksResolved {
+        return e.fallbacks
+    }
+
+    for _, family := range e.repo.Fallbacks {
+        d := e.Source.Descriptor
+        d.Family = family
 
-    hbFont := HBFont(ttf)
+        source := e.repo.match(d)
+        if source == nil || source == e.Source {
+            continue
+        }
+
+        e.fallbacks = append(e.fallbacks, e.repo.entry(source, e.Size))
+    }
+
+    e.fallbacksResolved = true
+    return e.fallbacks
+}
 
-    r.Add(
-        face,
-        hbFont,
-        ttf,
-    )
+// Parses the font file and makes it available for lookups.
+// The family and style are taken from the font itself.
+func (r *FontRepo) Load(path string) (*FontSource, error) {
+    return r.LoadFace(path, 0)
+}
+
+// Same as Load, but for a font within a collection
+func (r *FontRepo) LoadFace(path string, index int) (*FontSource, error) {
+    data, err := os.ReadFile(path)
+    if err != nil {
+        return nil, err
+    }
+
+    faces, err := truetype.Load(bytes.NewReader(data))
+    if err != nil {
+        return nil, fmt.Errorf("parsing %s: %w", path, err)
+    }
+
+    if index < 0 || index >= len(faces) {
+        return nil, fmt.Errorf("%s has no font %d", path, index)
+    }
+
+    ttf := faces[index].(*truetype.Font)
+    source := r.Add(path, data, ttf, DescribeFont(ttf))
+    source.Index = index
+
+    return source, nil
+}
+
+// Loads every style of the family found in the index
+func (r *FontRepo) LoadFamily(idx *FontIndex, family string) error {
+    positions := idx.families[strings.ToLower(family)]
+    if len(positions) == 0 {
+        return fmt.Errorf("font family %s not found", family)
+    }
+
+    for _, i := range positions {
+        f := idx.Files[i]
+        if _, err := r.LoadFace(f.Path, f.Index); err != nil {
+            return err
+        }
+    }
+
+    return nil
 }
 
 func (r *FontRepo) Add(
-    fontFace *freetype.Face,
-    hbFont *harfbuzz.Font,
+    path string,
+    data []byte,
     ttf *truetype.Font,
-) {
-    r.entries = append(r.entries, FontRepoEntry {
-        FontFace: fontFace,
-        HbFont: hbFont,
+    descriptor FontDescriptor,
+) *FontSource {
+    source := &FontSource {
+        Id: len(r.sources),
+        Descriptor: descriptor,
+        Path: path,
         Ttf: ttf,
-    })
+        HbFont: HBFont(ttf),
+        data: data,
+    }
+
+    r.sources = append(r.sources, source)
+
+    if r.Default.Family == "" {
+        r.Default = FontQuery {
+            FontDescriptor: descriptor,
+            Size: DEFAULT_FONT_SIZE,
+        }
+    }
+
+    return source
 }
 
-type Fonts struct {
-	font *[]*truetype.Font
+// Family and style as stated by the font
+func DescribeFont(ttf *truetype.Font) FontDescriptor {
+    d, _ := describe(ttf.Names, ttf.OS2)
+    return d
 }
 
-func LoadFont(path string) *Fonts {
-	f := Fonts {
-		font: &[]*truetype.Font{},
+func LoadTTF(path string) (*truetype.Font, error) {
+	file, err := os.Open(path)
+	
+	if err != nil {
+		return nil, err
 	}
+	defer file.Close()
 
-	data, err := os.ReadFile(path)
+	font, err := truetype.Parse(file)
 
+	return font, err
+}
+
+func newFace(lib *freetype.Library, data []byte, index int, px float32) (*freetype.Face, error) {
+	face, err := freetype.NewFace(lib, data, index)
 	if err != nil {
-		panic(err)
+		return nil, err
 	}
 
-	reader := bytes.NewReader(data)
-	ttf, err := truetype.Parse(reader)
+	pt := int(PxToPt(px))
 
-	*f.font = append(*f.font, ttf)
+	if globals.LOG_FONT {
+		fmt.Printf("Retrieving font face of size %vpt\n", pt)
+	}
+	
+	err = face.Pt(pt, int(PIXELS_PER_LOGICAL_INCH))
+	if err != nil {
+		face.Done()
+		return nil, err
+	}
 
-	return &f
+	return face, nil
 }
 
 func HBFont(font *truetype.Font) *harfbuzz.Font {
 	return harfbuzz.NewFont(font)
 }
 
-func GetSomeFont() string {
-	//fs, err := findfont.Find("Noto Sans", findfont.FontRegular)
+// Path of the regular style of the first of DEFAULT_FAMILIES
+// installed, or of any font, if none of them is.
+// Fails, if no fonts are installed at all.
+func GetSomeFont() (string, error) {
+	fonts := SystemFonts()
+
+	for _, family := range DEFAULT_FAMILIES {
+		if f, ok := fonts.Find(FontDescriptor{ Family: family, Weight: WeightRegular }); ok && f.Index == 0 {
+			return f.Path, nil
+		}
+	}
 
-	//if err != nil {
-	//	panic(err)
-	//}
+	for _, f := range fonts.Files {
+		if f.Index == 0 {
+			return f.Path, nil
+		}
+	}
 
-	//path := fs[0][2]
-	return "/usr/share/fonts/truetype/ubuntu/Ubuntu-R.ttf"
+	return "", fmt.Errorf("no fonts found, add a directory containing some to %s", FONT_PATH_ENV)
 }
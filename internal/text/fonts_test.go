@@ -0,0 +1,139 @@
+package text
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func LoadTestRepo(t *testing.T, files ...string) *FontRepo {
+	var repo FontRepo
+
+	for _, f := range files {
+		if _, err := repo.Load(filepath.Join(testFontsDir(), f)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return &repo
+}
+
+func TestDescriptorIsReadFromFont(t *testing.T) {
+	repo := LoadTestRepo(t, "Go-Regular.ttf", "Go-Bold.ttf")
+
+	regular := repo.Get().Source.Descriptor
+	if regular.Family != "Go" || regular.Weight != WeightRegular || regular.Italic {
+		t.Fatalf("Unexpected descriptor of Go-Regular: %+v", regular)
+	}
+
+	// Go-Bold states a weight class of 600
+	bold := repo.sources[1].Descriptor
+	if bold.Family != "Go" || bold.Weight <= WeightMedium {
+		t.Fatalf("Unexpected descriptor of Go-Bold: %+v", bold)
+	}
+}
+
+func TestFindClosestWeight(t *testing.T) {
+	repo := LoadTestRepo(t, "Go-Regular.ttf", "Go-Bold.ttf")
+
+	q := FontQuery{FontDescriptor: FontDescriptor{Family: "go", Weight: WeightBlack}, Size: 20}
+	font := repo.Find(q)
+
+	if font.Source != repo.sources[1] {
+		t.Fatalf("Expected the bold font for weight %d, got %+v", q.Weight, font.Source.Descriptor)
+	}
+
+	if font.Size != 20 {
+		t.Fatalf("Expected the requested size, got %f", font.Size)
+	}
+
+	if repo.Find(q) != font {
+		t.Fatalf("Expected faces to be reused for the same size")
+	}
+}
+
+func TestUnknownFamilyUsesDefault(t *testing.T) {
+	repo := LoadTestRepo(t, "Go-Regular.ttf", "cmapTest.ttf")
+
+	font := repo.Find(FontQuery{FontDescriptor: FontDescriptor{Family: "Missing"}})
+	if font == nil || font.Source.Descriptor.Family != "Go" {
+		t.Fatalf("Expected default family, got %v", font)
+	}
+}
+
+func TestMissingGlyphsAreShapedWithFallback(t *testing.T) {
+	repo := LoadTestRepo(t, "Go-Regular.ttf", "cmapTest.ttf")
+	repo.Fallbacks = []string{"cmapTest"}
+
+	font := repo.Get()
+	segment := CalculateSegment(font, "a中b")
+
+	if len(segment.Glyphs) != 3 {
+		t.Fatalf("Expected a glyph per rune, got %d", len(segment.Glyphs))
+	}
+
+	for i, g := range segment.Glyphs {
+		if g.GID == 0 {
+			t.Fatalf("Glyph %d is missing", i)
+		}
+
+		if g.Cluster != i {
+			t.Fatalf("Expected glyph %d to belong to rune %d, got %d", i, i, g.Cluster)
+		}
+	}
+
+	fallback := segment.Glyphs[1].Font
+	if fallback == font || fallback.Source.Descriptor.Family != "cmapTest" {
+		t.Fatalf("Expected 中 to be taken from the fallback, got %+v", fallback.Source.Descriptor)
+	}
+
+	if segment.Glyphs[0].Font != font || segment.Glyphs[2].Font != font {
+		t.Fatalf("Expected latin glyphs to be taken from the primary font")
+	}
+}
+
+func TestWithoutFallbackGlyphStaysMissing(t *testing.T) {
+	repo := LoadTestRepo(t, "Go-Regular.ttf")
+
+	segment := CalculateSegment(repo.Get(), "中")
+	if len(segment.Glyphs) != 1 || segment.Glyphs[0].GID != 0 {
+		t.Fatalf("Expected a single missing glyph, got %+v", segment.Glyphs)
+	}
+}
+
+func TestMetricsScaleWithSize(t *testing.T) {
+	repo := LoadTestRepo(t, "Go-Regular.ttf")
+
+	small := repo.Find(FontQuery{Size: 16}).Metrics
+	large := repo.Find(FontQuery{Size: 32}).Metrics
+
+	if small.Ascent <= 0 || small.Descent <= 0 {
+		t.Fatalf("Expected ascent and descent to be positive, got %+v", small)
+	}
+
+	if !nearly(large.LineHeight(), 2*small.LineHeight()) {
+		t.Fatalf("Expected the line height to double with the size, got %f and %f", small.LineHeight(), large.LineHeight())
+	}
+
+	if b := large.Baseline(large.LineHeight()); b < large.Ascent || b > large.Ascent+large.LineGap {
+		t.Fatalf("Expected the baseline below the ascent, got %f", b)
+	}
+}
+
+func TestFacesShareALibrary(t *testing.T) {
+	repo := LoadTestRepo(t, "Go-Regular.ttf")
+
+	for _, size := range []float32{16, 32} {
+		if _, err := repo.Find(FontQuery{Size: size}).Face(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	library := repo.library
+	if _, err := repo.Find(FontQuery{Size: 24}).Face(); err != nil || repo.library != library {
+		t.Fatalf("Expected another size to reuse the library, got %v", err)
+	}
+
+	if err := repo.Close(); err != nil || repo.library != nil || repo.Find(FontQuery{Size: 16}).face != nil {
+		t.Fatalf("Expected the faces and the library to be freed, got %v", err)
+	}
+}
@@ -0,0 +1,230 @@
+package text
+
+import (
+	"github.com/benoitkugler/textlayout/harfbuzz"
+	"github.com/benoitkugler/textlayout/language"
+	"golang.org/x/text/unicode/bidi"
+)
+
+// A run of text with a single direction and script,
+// which can be shaped in one go
+type Item struct {
+	// rune indices of the run
+	Start int
+	End   int
+	// bidi embedding level, odd levels are right to left
+	Level  int
+	Script language.Script
+}
+
+func (it Item) Direction() harfbuzz.Direction {
+	if it.Level%2 == 1 {
+		return harfbuzz.RightToLeft
+	}
+	return harfbuzz.LeftToRight
+}
+
+// Splits the text into runs of the same direction and script.
+// Directions are resolved paragraph by paragraph with the Unicode
+// bidi algorithm, items are returned in logical order.
+func Itemize(rs []rune) []Item {
+	var items []Item
+
+	start := 0
+	for i, r := range rs {
+		if bidiClass(r) == bidi.B {
+			items = append(items, itemizeParagraph(rs, start, i+1)...)
+			start = i + 1
+		}
+	}
+
+	if start < len(rs) {
+		items = append(items, itemizeParagraph(rs, start, len(rs))...)
+	}
+
+	return items
+}
+
+func bidiClass(r rune) bidi.Class {
+	props, _ := bidi.LookupRune(r)
+	return props.Class()
+}
+
+// Level of the paragraph, taken from its first strong character (P2, P3)
+func paragraphLevel(rs []rune) int {
+	for _, r := range rs {
+		switch bidiClass(r) {
+		case bidi.L:
+			return 0
+		case bidi.R, bidi.AL:
+			return 1
+		}
+	}
+	return 0
+}
+
+// Items of rs[start:end], which ends with a paragraph
+// separator, unless it is the last paragraph
+func itemizeParagraph(rs []rune, start, end int) []Item {
+	paragraph := rs[start:end]
+	base := paragraphLevel(paragraph)
+
+	// the separator itself is not passed to the bidi algorithm
+	// and stays at the paragraph level
+	text := paragraph
+	if bidiClass(text[len(text)-1]) == bidi.B {
+		text = text[:len(text)-1]
+	}
+
+	var directional []Item
+
+	if len(text) > 0 {
+		var p bidi.Paragraph
+		p.SetString(string(text))
+
+		order, err := p.Order()
+		if err != nil {
+			// not expected without options, shape it as a whole
+			directional = append(directional, Item{Start: start, End: start + len(text), Level: base})
+		}
+
+		for i := 0; err == nil && i < order.NumRuns(); i++ {
+			run := order.Run(i)
+			first, last := run.Pos()
+
+			directional = appendRun(directional, rs, start+first, start+last+1, run.Direction(), base)
+		}
+	}
+
+	if len(text) < len(paragraph) {
+		directional = append(directional, Item{Start: end - 1, End: end, Level: base})
+	}
+
+	var items []Item
+	for _, it := range directional {
+		items = append(items, splitByScript(rs, it)...)
+	}
+
+	return items
+}
+
+// The ordering only tells the direction of each run, so levels are
+// derived from the paragraph level. Numbers after right to left text
+// in a left to right paragraph are embedded within it like W7 and I2
+// would do, though the ordering merges them with the text after them.
+func appendRun(items []Item, rs []rune, start, end int, dir bidi.Direction, base int) []Item {
+	if dir == bidi.RightToLeft {
+		return append(items, Item{Start: start, End: end, Level: base | 1})
+	}
+
+	if base%2 == 1 {
+		return append(items, Item{Start: start, End: end, Level: base + 1})
+	}
+
+	if len(items) > 0 && items[len(items)-1].Level%2 == 1 {
+		numbers := start
+		for i := start; i < end; i++ {
+			c := bidiClass(rs[i])
+			if c == bidi.L {
+				break
+			}
+			if c == bidi.EN || c == bidi.AN {
+				numbers = i + 1
+			}
+		}
+
+		if numbers > start {
+			items = append(items, Item{Start: start, End: numbers, Level: base + 2})
+			start = numbers
+		}
+	}
+
+	if start < end {
+		items = append(items, Item{Start: start, End: end, Level: base})
+	}
+
+	return items
+}
+
+// Splits the item at script changes. Common and inherited characters,
+// like spaces, punctuation and combining marks, belong to the script
+// before them or to the one after them at the start of the item.
+func splitByScript(rs []rune, it Item) []Item {
+	var items []Item
+
+	current := it
+	current.Script = language.Common
+
+	for i := it.Start; i < it.End; i++ {
+		script := language.LookupScript(rs[i])
+		if !script.IsRealScript() {
+			continue
+		}
+
+		if !current.Script.IsRealScript() {
+			current.Script = script
+			continue
+		}
+
+		if script != current.Script {
+			current.End = i
+			items = append(items, current)
+
+			current = it
+			current.Start = i
+			current.Script = script
+		}
+	}
+
+	// extend the last script over trailing common characters,
+	// items without any script are shaped as Latin
+	if !current.Script.IsRealScript() {
+		current.Script = language.Latin
+	}
+	current.End = it.End
+	items = append(items, current)
+
+	return items
+}
+
+// Reorders the levels of a line for display, following L2. Returns
+// the positions in visual order, i.e. order[0] is drawn first.
+func VisualOrder(levels []int) []int {
+	order := make([]int, len(levels))
+	highest := 0
+	lowestOdd := -1
+
+	for i, l := range levels {
+		order[i] = i
+		highest = max(highest, l)
+		if l%2 == 1 && (lowestOdd < 0 || l < lowestOdd) {
+			lowestOdd = l
+		}
+	}
+
+	if lowestOdd < 0 {
+		return order
+	}
+
+	for level := highest; level >= lowestOdd; level-- {
+		for i := 0; i < len(order); {
+			if levels[order[i]] < level {
+				i += 1
+				continue
+			}
+
+			j := i
+			for j < len(order) && levels[order[j]] >= level {
+				j += 1
+			}
+
+			for a, b := i, j-1; a < b; a, b = a+1, b-1 {
+				order[a], order[b] = order[b], order[a]
+			}
+
+			i = j
+		}
+	}
+
+	return order
+}
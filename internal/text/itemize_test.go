@@ -0,0 +1,91 @@
+package text
+
+import (
+	"testing"
+
+	"github.com/benoitkugler/textlayout/language"
+)
+
+func TestItemizeByDirection(t *testing.T) {
+	// "abc", Hebrew "shalom" and a number
+	items := Itemize([]rune("abc שלום 12 def"))
+
+	expected := []Item{
+		{Start: 0, End: 4, Level: 0, Script: language.Latin},
+		{Start: 4, End: 9, Level: 1, Script: language.Hebrew},
+		{Start: 9, End: 11, Level: 2, Script: language.Latin},
+		{Start: 11, End: 15, Level: 0, Script: language.Latin},
+	}
+
+	if len(items) != len(expected) {
+		t.Fatalf("Expected %d items, got %+v", len(expected), items)
+	}
+
+	for i := range items {
+		if items[i] != expected[i] {
+			t.Fatalf("Item %d: expected %+v, got %+v", i, expected[i], items[i])
+		}
+	}
+}
+
+func TestItemizeByScript(t *testing.T) {
+	// Cyrillic and Greek, both left to right
+	items := Itemize([]rune("мир, κόσμος"))
+
+	if len(items) != 2 {
+		t.Fatalf("Expected two items, got %+v", items)
+	}
+
+	if items[0].Script != language.Cyrillic || items[0].End != 5 {
+		t.Fatalf("Expected the comma and space to stay with the Cyrillic run, got %+v", items[0])
+	}
+
+	if items[1].Script != language.Greek || items[1].Level != 0 {
+		t.Fatalf("Unexpected second item %+v", items[1])
+	}
+}
+
+func TestParagraphDirection(t *testing.T) {
+	items := Itemize([]rune("שלום abc\nabc"))
+
+	if items[0].Level != 1 {
+		t.Fatalf("Expected a right to left paragraph, got %+v", items[0])
+	}
+
+	last := items[len(items)-1]
+	if last.Level != 0 || last.Start != 9 {
+		t.Fatalf("Expected the second paragraph to be left to right, got %+v", last)
+	}
+}
+
+func TestVisualOrder(t *testing.T) {
+	order := VisualOrder([]int{0, 1, 1, 2, 2, 1, 0})
+	expected := []int{0, 5, 3, 4, 2, 1, 6}
+
+	for i := range order {
+		if order[i] != expected[i] {
+			t.Fatalf("Expected %v, got %v", expected, order)
+		}
+	}
+}
+
+func TestRightToLeftLineIsReversed(t *testing.T) {
+	font := LoadTestFont()
+	placement := PlaceSegments("ab אבג", font, 1000, TextLayoutOptions{LineHeight: 32})
+
+	if len(placement.PlacedSegments) != 1 {
+		t.Fatalf("Expected the line to be merged, got %d segments", len(placement.PlacedSegments))
+	}
+
+	var clusters []int
+	for _, g := range placement.PlacedSegments[0].Segment.Glyphs {
+		clusters = append(clusters, g.Cluster)
+	}
+
+	expected := []int{0, 1, 2, 5, 4, 3}
+	for i := range expected {
+		if clusters[i] != expected[i] {
+			t.Fatalf("Expected clusters %v, got %v", expected, clusters)
+		}
+	}
+}
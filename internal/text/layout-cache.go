@@ -0,0 +1,134 @@
+package text
+
+import (
+	"dyiui/internal/lru"
+	"encoding/binary"
+	"math"
+)
+
+// Frames, after which layouts nobody asked for are dropped
+const LAYOUT_CACHE_FRAMES = 60
+
+type segmentKey struct {
+	text string
+	font FontId
+	// in pixels
+	size float32
+}
+
+type layoutKey struct {
+	segmentKey
+	width float32
+	opts  TextLayoutOptions
+}
+
+// Rich text is known by the text and font of its spans,
+// which is all its placement depends on
+type richKey struct {
+	spans string
+	segmentKey
+	width float32
+	opts  TextLayoutOptions
+}
+
+// Keeps shaped and placed text across frames, so labels, which
+// do not change, are not shaped again every frame. Layouts are
+// dropped, once they were not used for MaxAge frames.
+type LayoutCache struct {
+	// LAYOUT_CACHE_FRAMES, if 0
+	MaxAge uint64
+	Stats  lru.Stats
+
+	segments lru.AgingCache[segmentKey, Segment]
+	layouts  lru.AgingCache[layoutKey, RenderTextResult]
+	rich     lru.AgingCache[richKey, RenderTextResult]
+}
+
+// Same as CalculateSegment, the result must not be modified
+func (c *LayoutCache) Shape(font *FontRepoEntry, text string) Segment {
+	key := segmentKey{text, font.Id, font.Size}
+
+	if v, ok := c.segments.Get(key); ok {
+		c.Stats.Hits += 1
+		return v
+	}
+
+	c.Stats.Misses += 1
+	segment := CalculateSegment(font, text)
+	c.segments.Store(key, segment)
+
+	return segment
+}
+
+// Same as PlaceSegments, the result must not be modified
+func (c *LayoutCache) Place(
+	text string,
+	font *FontRepoEntry,
+	allowedWidth float32,
+	opts TextLayoutOptions,
+) RenderTextResult {
+	key := layoutKey{segmentKey{text, font.Id, font.Size}, allowedWidth, opts}
+
+	if v, ok := c.layouts.Get(key); ok {
+		c.Stats.Hits += 1
+		return v
+	}
+
+	c.Stats.Misses += 1
+	placement := PlaceSegments(text, font, allowedWidth, opts)
+	c.layouts.Store(key, placement)
+
+	return placement
+}
+
+// Same as PlaceRichText, the result must not be modified
+func (c *LayoutCache) PlaceRich(
+	text RichText,
+	font *FontRepoEntry,
+	allowedWidth float32,
+	opts TextLayoutOptions,
+) RenderTextResult {
+	key := richKey{spansKey(text, font), segmentKey{"", font.Id, font.Size}, allowedWidth, opts}
+
+	if v, ok := c.rich.Get(key); ok {
+		c.Stats.Hits += 1
+		return v
+	}
+
+	c.Stats.Misses += 1
+	placement := PlaceRichText(text, font, allowedWidth, opts)
+	c.rich.Store(key, placement)
+
+	return placement
+}
+
+// Font, size and length of every span followed by its text
+func spansKey(text RichText, font *FontRepoEntry) string {
+	var buf []byte
+	for _, s := range text {
+		f := s.FontOf(font)
+		buf = binary.AppendUvarint(buf, uint64(f.Id))
+		buf = binary.AppendUvarint(buf, uint64(math.Float32bits(f.Size)))
+		buf = binary.AppendUvarint(buf, uint64(len(s.Text)))
+		buf = append(buf, s.Text...)
+	}
+	return string(buf)
+}
+
+// Cached segments and layouts
+func (c *LayoutCache) Len() int {
+	return c.segments.Len() + c.layouts.Len() + c.rich.Len()
+}
+
+// Advances to the next frame and drops the
+// entries, which are too old by then
+func (c *LayoutCache) NextFrame() {
+	maxAge := c.MaxAge
+	if maxAge == 0 {
+		maxAge = LAYOUT_CACHE_FRAMES
+	}
+
+	c.Stats.Evictions += c.segments.NextFrame(maxAge)
+	c.Stats.Evictions += c.layouts.NextFrame(maxAge)
+	c.Stats.Evictions += c.rich.NextFrame(maxAge)
+}
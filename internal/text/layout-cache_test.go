@@ -0,0 +1,59 @@
+package text
+
+import "testing"
+
+func TestLayoutCacheReusesPlacements(t *testing.T) {
+	font := LoadTestFont()
+	var cache LayoutCache
+
+	first := cache.Place("label", font, 100, TextLayoutOptions{})
+	second := cache.Place("label", font, 100, TextLayoutOptions{})
+
+	if &first.PlacedSegments[0] != &second.PlacedSegments[0] {
+		t.Fatalf("Expected the placement to be reused")
+	}
+
+	cache.Place("label", font, 50, TextLayoutOptions{})
+	if cache.Stats.Hits != 1 || cache.Stats.Misses != 2 {
+		t.Fatalf("Expected another width to be placed again, got %+v", cache.Stats)
+	}
+}
+
+func TestLayoutCacheDropsUnusedEntries(t *testing.T) {
+	font := LoadTestFont()
+	cache := LayoutCache{MaxAge: 2}
+
+	cache.Shape(font, "old")
+	cache.Shape(font, "used")
+
+	for i := 0; i < 3; i++ {
+		cache.NextFrame()
+		cache.Shape(font, "used")
+	}
+
+	if cache.Len() != 1 || cache.Stats.Evictions != 1 {
+		t.Fatalf("Expected only the used entry to be kept, got %d entries, %+v", cache.Len(), cache.Stats)
+	}
+}
+
+func TestLayoutCacheReusesRichText(t *testing.T) {
+	font := LoadTestFont()
+	var cache LayoutCache
+
+	text := RichText{{Text: "red", Color: 0xff0000ff}, {Text: " text"}}
+	cache.PlaceRich(text, font, 100, TextLayoutOptions{})
+
+	// colors and decorations do not change the placement
+	text[0].Color = 0x00ff00ff
+	text[1].Underline = true
+	cache.PlaceRich(text, font, 100, TextLayoutOptions{})
+
+	// spans split elsewhere or at another size are placed
+	// again, glyphs tell their span by index
+	cache.PlaceRich(RichText{{Text: "red "}, {Text: "text"}}, font, 100, TextLayoutOptions{})
+	cache.PlaceRich(RichText{{Text: "red", Size: 20}, {Text: " text"}}, font, 100, TextLayoutOptions{})
+
+	if cache.Stats.Hits != 1 || cache.Stats.Misses != 3 {
+		t.Fatalf("Expected only the recolored text to be reused, got %+v", cache.Stats)
+	}
+}
@@ -0,0 +1,309 @@
+package text
+
+import (
+	"unicode"
+
+	"github.com/benoitkugler/textlayout/unicodedata"
+)
+
+type BreakAction = int
+
+const (
+	// the line must not be broken here
+	BreakProhibited BreakAction = iota
+	// the line may be broken here
+	BreakAllowed
+	// the line has to be broken here, e.g. after \n
+	BreakMandatory
+)
+
+type breakClass int
+
+// Line breaking classes of UAX #14, see
+// https://www.unicode.org/reports/tr14/#Table1
+const (
+	clsAL breakClass = iota
+	clsBA
+	clsBB
+	clsB2
+	clsBK
+	clsCB
+	clsCL
+	clsCM
+	clsCP
+	clsCR
+	clsEB
+	clsEM
+	clsEX
+	clsGL
+	clsH2
+	clsH3
+	clsHL
+	clsHY
+	clsID
+	clsIN
+	clsIS
+	clsJL
+	clsJT
+	clsJV
+	clsLF
+	clsNL
+	clsNS
+	clsNU
+	clsOP
+	clsPO
+	clsPR
+	clsQU
+	clsRI
+	clsSP
+	clsSY
+	clsWJ
+	clsZW
+	clsZWJ
+)
+
+var breakClasses = map[*unicode.RangeTable]breakClass{
+	unicodedata.BreakAL:  clsAL,
+	unicodedata.BreakBA:  clsBA,
+	unicodedata.BreakBB:  clsBB,
+	unicodedata.BreakB2:  clsB2,
+	unicodedata.BreakBK:  clsBK,
+	unicodedata.BreakCB:  clsCB,
+	unicodedata.BreakCL:  clsCL,
+	unicodedata.BreakCM:  clsCM,
+	unicodedata.BreakCP:  clsCP,
+	unicodedata.BreakCR:  clsCR,
+	unicodedata.BreakEB:  clsEB,
+	unicodedata.BreakEM:  clsEM,
+	unicodedata.BreakEX:  clsEX,
+	unicodedata.BreakGL:  clsGL,
+	unicodedata.BreakH2:  clsH2,
+	unicodedata.BreakH3:  clsH3,
+	unicodedata.BreakHL:  clsHL,
+	unicodedata.BreakHY:  clsHY,
+	unicodedata.BreakID:  clsID,
+	unicodedata.BreakIN:  clsIN,
+	unicodedata.BreakIS:  clsIS,
+	unicodedata.BreakJL:  clsJL,
+	unicodedata.BreakJT:  clsJT,
+	unicodedata.BreakJV:  clsJV,
+	unicodedata.BreakLF:  clsLF,
+	unicodedata.BreakNL:  clsNL,
+	unicodedata.BreakNS:  clsNS,
+	unicodedata.BreakNU:  clsNU,
+	unicodedata.BreakOP:  clsOP,
+	unicodedata.BreakPO:  clsPO,
+	unicodedata.BreakPR:  clsPR,
+	unicodedata.BreakQU:  clsQU,
+	unicodedata.BreakRI:  clsRI,
+	unicodedata.BreakSP:  clsSP,
+	unicodedata.BreakSY:  clsSY,
+	unicodedata.BreakWJ:  clsWJ,
+	unicodedata.BreakZW:  clsZW,
+	unicodedata.BreakZWJ: clsZWJ,
+	// resolved as described by LB1
+	unicodedata.BreakCJ: clsNS,
+}
+
+// Class of the rune after applying LB1
+func lineBreakClass(r rune) breakClass {
+	table := unicodedata.LookupLineBreakClass(r)
+
+	if c, ok := breakClasses[table]; ok {
+		return c
+	}
+
+	// SA, the scripts needing a dictionary to break words,
+	// are treated like letters, their marks are combining
+	if table == unicodedata.BreakSA && (unicode.Is(unicode.Mn, r) || unicode.Is(unicode.Mc, r)) {
+		return clsCM
+	}
+
+	// AI, SG, XX and SA
+	return clsAL
+}
+
+// Tells for every position in rs, whether the line may be broken there.
+// breaks[i] is the opportunity before rs[i], so breaks[0] is prohibited
+// and breaks[len(rs)] is mandatory. Follows the rules of UAX #14
+// without tailoring, some of the number rules are simplified to pairs.
+func LineBreaks(rs []rune) []BreakAction {
+	breaks := make([]BreakAction, len(rs)+1)
+	if len(rs) == 0 {
+		return breaks
+	}
+
+	// LB3
+	breaks[len(rs)] = BreakMandatory
+
+	// class of the character before the position, after
+	// attaching combining marks (LB9) and resolving them (LB10)
+	a := lineBreakClass(rs[0])
+	if a == clsCM || a == clsZWJ {
+		a = clsAL
+	}
+	// the one before a, for LB21a
+	beforeA := a
+	// last class before a run of spaces, for the rules with SP*
+	beforeSpaces := a
+	raw := lineBreakClass(rs[0])
+	regionalIndicators := 0
+	if a == clsRI {
+		regionalIndicators = 1
+	}
+
+	for i := 1; i < len(rs); i++ {
+		b := lineBreakClass(rs[i])
+		action := BreakAllowed
+		attached := false
+
+		switch {
+		// LB4, LB5
+		case a == clsBK:
+			action = BreakMandatory
+		case a == clsCR && b == clsLF:
+			action = BreakProhibited
+		case a == clsCR || a == clsLF || a == clsNL:
+			action = BreakMandatory
+		// LB6, LB7
+		case b == clsBK || b == clsCR || b == clsLF || b == clsNL:
+			action = BreakProhibited
+		case b == clsSP || b == clsZW:
+			action = BreakProhibited
+		// LB8
+		case beforeSpaces == clsZW && (a == clsZW || a == clsSP):
+			action = BreakAllowed
+		// LB8a
+		case raw == clsZWJ:
+			action = BreakProhibited
+		// LB9
+		case (b == clsCM || b == clsZWJ) && !isHardBreakOrSpace(a):
+			action = BreakProhibited
+			attached = true
+		default:
+			// LB10
+			if b == clsCM || b == clsZWJ {
+				b = clsAL
+			}
+			action = pairAction(a, b, beforeA, beforeSpaces, regionalIndicators)
+		}
+
+		breaks[i] = action
+		raw = lineBreakClass(rs[i])
+
+		// attached marks take the class of their base
+		if attached {
+			continue
+		}
+
+		if b == clsRI && a == clsRI {
+			regionalIndicators += 1
+		} else if b == clsRI {
+			regionalIndicators = 1
+		} else {
+			regionalIndicators = 0
+		}
+
+		if b != clsSP {
+			beforeSpaces = b
+		}
+
+		beforeA = a
+		a = b
+	}
+
+	return breaks
+}
+
+func isHardBreakOrSpace(c breakClass) bool {
+	return c == clsBK || c == clsCR || c == clsLF || c == clsNL || c == clsSP || c == clsZW
+}
+
+func isAny(c breakClass, classes ...breakClass) bool {
+	for _, o := range classes {
+		if c == o {
+			return true
+		}
+	}
+	return false
+}
+
+// LB11 to LB31 for the pair a b. beforeSpaces is the last
+// class before a, which is no space.
+func pairAction(a, b, beforeA, beforeSpaces breakClass, regionalIndicators int) BreakAction {
+	switch {
+	// LB11, LB12, LB12a
+	case a == clsWJ || b == clsWJ:
+		return BreakProhibited
+	case a == clsGL:
+		return BreakProhibited
+	case b == clsGL && !isAny(a, clsSP, clsBA, clsHY):
+		return BreakProhibited
+	// LB13
+	case isAny(b, clsCL, clsCP, clsEX, clsIS, clsSY):
+		return BreakProhibited
+	// LB14 to LB17, which look across spaces
+	case beforeSpaces == clsOP:
+		return BreakProhibited
+	case beforeSpaces == clsQU && b == clsOP:
+		return BreakProhibited
+	case isAny(beforeSpaces, clsCL, clsCP) && b == clsNS:
+		return BreakProhibited
+	case beforeSpaces == clsB2 && b == clsB2:
+		return BreakProhibited
+	// LB18
+	case a == clsSP:
+		return BreakAllowed
+	// LB19, LB20
+	case a == clsQU || b == clsQU:
+		return BreakProhibited
+	case a == clsCB || b == clsCB:
+		return BreakAllowed
+	// LB21, LB21a, LB21b
+	case isAny(b, clsBA, clsHY, clsNS) || a == clsBB:
+		return BreakProhibited
+	case beforeA == clsHL && isAny(a, clsHY, clsBA):
+		return BreakProhibited
+	case a == clsSY && b == clsHL:
+		return BreakProhibited
+	// LB22
+	case b == clsIN:
+		return BreakProhibited
+	// LB23, LB23a, LB24
+	case isAny(a, clsAL, clsHL) && b == clsNU, a == clsNU && isAny(b, clsAL, clsHL):
+		return BreakProhibited
+	case a == clsPR && isAny(b, clsID, clsEB, clsEM), isAny(a, clsID, clsEB, clsEM) && b == clsPO:
+		return BreakProhibited
+	case isAny(a, clsPR, clsPO) && isAny(b, clsAL, clsHL), isAny(a, clsAL, clsHL) && isAny(b, clsPR, clsPO):
+		return BreakProhibited
+	// LB25, as pairs
+	case isAny(a, clsCL, clsCP, clsNU) && isAny(b, clsPO, clsPR),
+		isAny(a, clsPO, clsPR) && isAny(b, clsOP, clsNU),
+		isAny(a, clsOP, clsHY, clsIS, clsNU, clsSY) && b == clsNU:
+		return BreakProhibited
+	// LB26, LB27
+	case a == clsJL && isAny(b, clsJL, clsJV, clsH2, clsH3),
+		isAny(a, clsJV, clsH2) && isAny(b, clsJV, clsJT),
+		isAny(a, clsJT, clsH3) && b == clsJT:
+		return BreakProhibited
+	case isAny(a, clsJL, clsJV, clsJT, clsH2, clsH3) && b == clsPO,
+		a == clsPR && isAny(b, clsJL, clsJV, clsJT, clsH2, clsH3):
+		return BreakProhibited
+	// LB28, LB29, LB30
+	case isAny(a, clsAL, clsHL) && isAny(b, clsAL, clsHL):
+		return BreakProhibited
+	case a == clsIS && isAny(b, clsAL, clsHL):
+		return BreakProhibited
+	case isAny(a, clsAL, clsHL, clsNU) && b == clsOP, a == clsCP && isAny(b, clsAL, clsHL, clsNU):
+		return BreakProhibited
+	// LB30a, flags are pairs of regional indicators
+	case a == clsRI && b == clsRI && regionalIndicators%2 == 1:
+		return BreakProhibited
+	// LB30b
+	case a == clsEB && b == clsEM:
+		return BreakProhibited
+	}
+
+	// LB31
+	return BreakAllowed
+}
@@ -0,0 +1,114 @@
+package text
+
+import (
+	"math"
+	"testing"
+)
+
+// Text with | at every position a line may be broken
+// and ! at every mandatory break
+func MarkBreaks(text string) string {
+	rs := []rune(text)
+	breaks := LineBreaks(rs)
+
+	var marked []rune
+	for i, r := range rs {
+		switch breaks[i] {
+		case BreakAllowed:
+			marked = append(marked, '|')
+		case BreakMandatory:
+			marked = append(marked, '!')
+		}
+		marked = append(marked, r)
+	}
+
+	return string(marked)
+}
+
+func TestBreakOpportunities(t *testing.T) {
+	cases := map[string]string{
+		"Hello world":     "Hello |world",
+		"well-known fact": "well-|known |fact",
+		"one\ntwo":        "one\n!two",
+		"crlf\r\nend":     "crlf\r\n!end",
+		"(a) b.":          "(a) |b.",
+		"3.14 m":          "3.14 |m",
+		"中文字":             "中|文|字",
+		"a\u00a0b":        "a\u00a0b",
+		"$100 now":        "$100 |now",
+	}
+
+	for text, expected := range cases {
+		StringsShouldEqual(t, expected, MarkBreaks(text))
+	}
+}
+
+func TestCombiningMarksStayWithTheirBase(t *testing.T) {
+	// e followed by a combining acute accent
+	StringsShouldEqual(t, "cafe\u0301 |ok", MarkBreaks("cafe\u0301 ok"))
+}
+
+func TestMandatoryBreakStartsLine(t *testing.T) {
+	font := LoadTestFont()
+	placement := PlaceSegments("one\ntwo", font, 1000, TextLayoutOptions{LineHeight: 32})
+
+	if len(placement.PlacedSegments) != 2 {
+		t.Fatalf("Expected two segments, got %d", len(placement.PlacedSegments))
+	}
+
+	second := placement.PlacedSegments[1]
+	if second.XOffset != 0 || second.YOffset != 32 || placement.Height != 64 {
+		t.Fatalf("Expected second word on the next line, got %+v with height %f", second, placement.Height)
+	}
+
+	// the newline itself is not shaped
+	if len(placement.PlacedSegments[0].Segment.Glyphs) != 3 {
+		t.Fatalf("Expected only the glyphs of 'one', got %d", len(placement.PlacedSegments[0].Segment.Glyphs))
+	}
+}
+
+func TestSpaceAdvanceComesFromFont(t *testing.T) {
+	font := LoadTestFont()
+	placement := PlaceSegments("a b", font, 1000, TextLayoutOptions{LineHeight: 32})
+
+	expected := CalculateSegment(font, "a ").Width
+	actual := placement.PlacedSegments[1].XOffset
+
+	if math.Abs(float64(expected-actual)) > .001 {
+		t.Fatalf("Expected 'b' to start after the shaped space at %f, got %f", expected, actual)
+	}
+}
+
+func TestTrailingSpacesDoNotForceBreak(t *testing.T) {
+	font := LoadTestFont()
+	width := CalculateSegment(font, "one two").Width
+
+	placement := PlaceSegments("one two   ", font, width, TextLayoutOptions{LineHeight: 32})
+	if placement.Height != 32 {
+		t.Fatalf("Expected trailing spaces to hang, got height %f", placement.Height)
+	}
+}
+
+func TestLongWordsBreakBetweenCharacters(t *testing.T) {
+	font := LoadTestFont()
+	word := "Unbreakable"
+	width := CalculateSegment(font, word).Width / 2
+
+	placement := PlaceSegments(word, font, width, TextLayoutOptions{LineHeight: 32})
+
+	if placement.Height < 64 {
+		t.Fatalf("Expected word to span several lines, got height %f", placement.Height)
+	}
+
+	glyphs := 0
+	for _, p := range placement.PlacedSegments {
+		if p.XOffset != 0 || p.Segment.Width > width {
+			t.Fatalf("Expected every piece to start a line and fit into it, got %+v", p)
+		}
+		glyphs += len(p.Segment.Glyphs)
+	}
+
+	if glyphs != len(word) {
+		t.Fatalf("Expected all glyphs to be placed, got %d", glyphs)
+	}
+}
@@ -0,0 +1,110 @@
+package text
+
+import (
+	. "dyiui/internal/color"
+	. "dyiui/internal/types"
+	"strings"
+)
+
+// A part of a RichText with a style of its own
+type Span struct {
+	Text string
+	// nil for the font the text is placed with. Bold or
+	// italic spans take a font found with FontRepo.Find.
+	Font *FontRepoEntry
+	// in pixels, 0 for the size of the font
+	Size  float32
+	Color Color
+	// lines drawn along with the glyphs in their color
+	Underline     bool
+	Strikethrough bool
+	// drawn behind the glyphs, 0 for none
+	Background Color
+}
+
+// Text made of spans, which is broken into lines and shaped
+// as a whole, so words and runs of right to left text may
+// continue across spans. Glyphs tell their span by index.
+type RichText []Span
+
+// The text of all spans
+func (t RichText) String() string {
+	var sb strings.Builder
+	for _, s := range t {
+		sb.WriteString(s.Text)
+	}
+	return sb.String()
+}
+
+// The span is set in, font is the one the text is placed with
+func (s Span) FontOf(font *FontRepoEntry) *FontRepoEntry {
+	if s.Font != nil {
+		font = s.Font
+	}
+
+	if s.Size > 0 {
+		font = font.WithSize(s.Size)
+	}
+
+	return font
+}
+
+// Same as PlaceSegments for text made of spans. The line height
+// is taken from font, lines with spans in other fonts grow to fit.
+func PlaceRichText(
+	text RichText,
+	font *FontRepoEntry,
+	allowedWidth float32,
+	opts TextLayoutOptions,
+) RenderTextResult {
+	var rs []rune
+	runs := make([]fontRun, len(text))
+
+	for i, s := range text {
+		rs = append(rs, []rune(s.Text)...)
+		runs[i] = fontRun{len(rs), s.FontOf(font)}
+	}
+
+	return placeText(newLayoutText(rs, runs), font, allowedWidth, opts)
+}
+
+// Glyphs of the same span next to each other on a line
+type SpanRun struct {
+	Span int
+	// the glyphs are part of
+	Placed PlacedSegment
+	// extent of the advances of the glyphs
+	X0 float32
+	X1 float32
+	// the first of the glyphs was shaped with
+	Font *FontRepoEntry
+}
+
+// Extent of the run from the ascent to the descent of its font
+// relative to the top left corner of the text
+func (r SpanRun) Bounds() Quad {
+	m := r.Font.Metrics
+	top := r.Placed.YOffset + r.Placed.Baseline - m.Ascent
+	return NewQuad(r.X0, top, r.X1-r.X0, m.Ascent+m.Descent)
+}
+
+// Calls fn for every run of glyphs of the same span in visual order,
+// e.g. to highlight spans or to tell, which of them was clicked
+func EachSpanRun(placement RenderTextResult, fn func(r SpanRun)) {
+	for _, p := range placement.PlacedSegments {
+		glyphs := p.Segment.Glyphs
+		x := p.XOffset
+
+		for i := 0; i < len(glyphs); {
+			x0 := x
+			j := i
+			for j < len(glyphs) && glyphs[j].Span == glyphs[i].Span {
+				x += glyphs[j].XAdvance
+				j += 1
+			}
+
+			fn(SpanRun{Span: glyphs[i].Span, Placed: p, X0: x0, X1: x, Font: glyphs[i].Font})
+			i = j
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package text
+
+import (
+	"testing"
+)
+
+func TestGlyphsTellTheirSpan(t *testing.T) {
+	font := LoadTestFont()
+	text := RichText{{Text: "ab"}, {Text: ""}, {Text: "cd"}}
+
+	placement := PlaceRichText(text, font, 1000, TextLayoutOptions{})
+
+	var spans []int
+	for _, p := range placement.PlacedSegments {
+		for _, g := range p.Segment.Glyphs {
+			spans = append(spans, g.Span)
+		}
+	}
+
+	if len(spans) != 4 || spans[0] != 0 || spans[1] != 0 || spans[2] != 2 || spans[3] != 2 {
+		t.Fatalf("Expected glyphs of spans 0 and 2, got %v", spans)
+	}
+}
+
+func TestWordsContinueAcrossSpans(t *testing.T) {
+	font := LoadTestFont()
+	width := CalculateSegment(font, "abcd").Width
+
+	// the only break opportunity is after the space
+	text := RichText{{Text: "ab"}, {Text: "cd ef"}}
+	placement := PlaceRichText(text, font, width+1, TextLayoutOptions{LineHeight: 32})
+
+	lines := lineOffsets(placement)
+	if len(lines) != 2 {
+		t.Fatalf("Expected two lines, got %v", lines)
+	}
+}
+
+func TestLargerSpanGrowsItsLine(t *testing.T) {
+	font := LoadTestFont()
+	large := font.WithSize(font.Size * 2)
+
+	text := RichText{{Text: "small "}, {Text: "large", Size: large.Size}, {Text: "\nsmall"}}
+	placement := PlaceRichText(text, font, 1000, TextLayoutOptions{})
+
+	first := placement.PlacedSegments[0]
+	last := placement.PlacedSegments[len(placement.PlacedSegments)-1]
+
+	if first.Baseline < large.Metrics.Ascent {
+		t.Errorf("Expected room above the baseline for the large span, got %f", first.Baseline)
+	}
+
+	for _, p := range placement.PlacedSegments {
+		if p.YOffset == first.YOffset && p.Baseline != first.Baseline {
+			t.Errorf("Expected all spans of a line on one baseline, got %f and %f", p.Baseline, first.Baseline)
+		}
+	}
+
+	if last.YOffset < large.Metrics.LineHeight() {
+		t.Errorf("Expected the second line below the large span, starts at %f", last.YOffset)
+	}
+
+	if !nearly(last.Baseline, font.Metrics.Baseline(font.Metrics.LineHeight())) {
+		t.Errorf("Expected the second line to keep the height of the font")
+	}
+}
@@ -0,0 +1,622 @@
+package text
+
+import (
+	"image"
+	"unicode"
+
+	. "dyiui/internal/types"
+	. "dyiui/internal/units"
+
+	"github.com/benoitkugler/textlayout/fonts"
+	"github.com/benoitkugler/textlayout/fonts/truetype"
+	"github.com/benoitkugler/textlayout/harfbuzz"
+	"github.com/benoitkugler/textlayout/language"
+	"github.com/danielgatis/go-freetype/freetype"
+)
+
+func GetGlyphBitmap(gid fonts.GID, a *freetype.Face) (*image.RGBA, *freetype.Metrics, error) {
+    return a.GlyphByGid(int(gid))
+}
+
+type Glyph struct {
+	XAdvance float32
+	YAdvance float32
+	XOffset  float32
+	YOffset  float32
+    GID      fonts.GID
+	// index of the first rune the glyph was shaped from
+	Cluster  int
+	// bidi embedding level of the run, odd levels are right to left
+	Level    int
+	// index of the span of a RichText the glyph was shaped
+	// from, 0 for text placed with a single font
+	Span     int
+	// the glyph belongs to, differs from the requested
+	// font, if it was taken from a fallback
+	Font     *FontRepoEntry
+}
+
+type Segment struct {
+	Glyphs []Glyph
+	Width  float32
+}
+
+/*
+Split text into array of words,
+while ignoring whitespace
+*/
+func SplitIntoSegments(text string) []string {
+
+	var segs []string
+
+	withinWhitespaceRun := true
+	start := 0
+
+	for i, r := range text {
+		if unicode.IsSpace(r) {
+			if !withinWhitespaceRun {
+				segs = append(segs, text[start:i])
+				withinWhitespaceRun = true
+			}
+		} else if withinWhitespaceRun {
+			withinWhitespaceRun = false
+			start = i
+		}
+	}
+
+	if !withinWhitespaceRun {
+		segs = append(segs, text[start:])
+	}
+
+	return segs
+}
+
+// Shapes the text with the font. The text is itemized by direction
+// and script first, runs of glyphs the font lacks are reshaped with
+// its fallbacks, one after another. Glyphs are in logical order,
+// ReorderLine puts them into the order they are drawn in.
+func CalculateSegment(font *FontRepoEntry, text string) Segment {
+	rs := []rune(text)
+	t := newLayoutText(rs, []fontRun{{len(rs), font}})
+	return shapeItems(t, 0, len(rs))
+}
+
+// Runes up to End, starting after the previous
+// run, are shaped with the font
+type fontRun struct {
+	End  int
+	Font *FontRepoEntry
+}
+
+// Text being laid out, along with what is found out about it
+// once. Plain text is a single run, rich text one run per span.
+type layoutText struct {
+	rs     []rune
+	items  []Item
+	breaks []BreakAction
+	runs   []fontRun
+}
+
+func newLayoutText(rs []rune, runs []fontRun) *layoutText {
+	return &layoutText{
+		rs:     rs,
+		items:  Itemize(rs),
+		breaks: LineBreaks(rs),
+		runs:   runs,
+	}
+}
+
+// Shapes the parts of the items within rs[start:end], each with
+// the font of its run. Clusters of the glyphs are indices into rs.
+func shapeItems(t *layoutText, start, end int) Segment {
+	var segment Segment
+
+	for _, it := range t.items {
+		runStart := 0
+
+		for span, run := range t.runs {
+			from := max(start, it.Start, runStart)
+			to := min(end, it.End, run.End)
+			runStart = run.End
+
+			if from >= to {
+				continue
+			}
+
+			chain := append([]*FontRepoEntry{run.Font}, run.Font.Fallbacks()...)
+			glyphs := shapeWithFallback(chain, t.rs, from, to, it)
+			for i := range glyphs {
+				glyphs[i].Span = span
+			}
+
+			segment.Glyphs = append(segment.Glyphs, glyphs...)
+		}
+	}
+
+	for _, g := range segment.Glyphs {
+		segment.Width += g.XAdvance
+	}
+
+	return segment
+}
+
+// Shapes rs[start:end] with the first font of the chain and replaces
+// every run of missing glyphs by shaping its runes with the rest of it.
+// The runes around the range are passed along as context.
+func shapeWithFallback(chain []*FontRepoEntry, rs []rune, start, end int, it Item) []Glyph {
+	glyphs := shape(chain[0], rs, start, end, it)
+
+	if len(chain) == 1 {
+		return glyphs
+	}
+
+	var result []Glyph
+
+	for i := 0; i < len(glyphs); {
+		if glyphs[i].GID != 0 {
+			result = append(result, glyphs[i])
+			i += 1
+			continue
+		}
+
+		j := i
+		for j < len(glyphs) && glyphs[j].GID == 0 {
+			j += 1
+		}
+
+		runStart := glyphs[i].Cluster
+		runEnd := end
+		if j < len(glyphs) {
+			runEnd = glyphs[j].Cluster
+		}
+
+		if runEnd > runStart {
+			result = append(result, shapeWithFallback(chain[1:], rs, runStart, runEnd, it)...)
+		} else {
+			// clusters were merged with a glyph the font has
+			result = append(result, glyphs[i:j]...)
+		}
+
+		i = j
+	}
+
+	return result
+}
+
+// Shapes rs[start:end] as part of the item. Glyphs are returned
+// in logical order, also for right to left text.
+func shape(font *FontRepoEntry, rs []rune, start, end int, it Item) []Glyph {
+	buf := harfbuzz.NewBuffer()
+
+	buf.AddRunes(rs, start, end-start)
+	buf.Props.Direction = it.Direction()
+	buf.Props.Language = language.DefaultLanguage()
+	buf.Props.Script = it.Script
+	buf.Shape(font.HbFont, []harfbuzz.Feature{})
+	metric := GetDefaultMetric()
+	factor := float32(FontScaleFactor(font.Ttf, metric, Sp(font.Size)))
+
+	glyphs := make([]Glyph, 0, len(buf.Pos))
+
+	for i, g := range buf.Pos {
+		glyphs = append(glyphs, Glyph{
+			XAdvance: float32(g.XAdvance) * factor,
+			YAdvance: float32(g.YAdvance) * factor,
+			XOffset:  float32(g.XOffset) * factor,
+			YOffset:  float32(g.YOffset) * factor,
+			GID:      buf.Info[i].Glyph,
+			Cluster:  buf.Info[i].Cluster,
+			Level:    it.Level,
+			Font:     font,
+		})
+	}
+
+	// harfbuzz returns right to left runs in visual order
+	if it.Direction() == harfbuzz.RightToLeft {
+		reverseGlyphs(glyphs)
+	}
+
+	return glyphs
+}
+
+func reverseGlyphs(glyphs []Glyph) {
+	for a, b := 0, len(glyphs)-1; a < b; a, b = a+1, b-1 {
+		glyphs[a], glyphs[b] = glyphs[b], glyphs[a]
+	}
+}
+
+// Puts the glyphs of a line into the order they are drawn in.
+// Runs are reordered by their bidi levels, which also reverses
+// the glyphs of right to left runs.
+func ReorderLine(segment Segment) Segment {
+	levels := make([]int, len(segment.Glyphs))
+	mixed := false
+
+	for i, g := range segment.Glyphs {
+		levels[i] = g.Level
+		mixed = mixed || g.Level > 0
+	}
+
+	if !mixed {
+		return segment
+	}
+
+	glyphs := make([]Glyph, 0, len(segment.Glyphs))
+	for _, i := range VisualOrder(levels) {
+		glyphs = append(glyphs, segment.Glyphs[i])
+	}
+
+	return Segment{Glyphs: glyphs, Width: segment.Width}
+}
+
+func FontScaleFactor(font *truetype.Font, m Metric, size Sp) float32 {
+	sizePx := m.Sp(size)
+
+	upem := font.Upem()
+	factor := float32(sizePx) / float32(upem)
+	return factor
+}
+
+// Breaks the text into lines no wider than allowedWidth.
+// Lines are broken at the opportunities found by LineBreaks and
+// always at mandatory breaks. Words wider than a line are broken
+// between characters. Every piece between two opportunities is
+// shaped and placed as a segment of its own, lines with right to
+// left text are merged into one segment in visual order.
+// The lines are then truncated and aligned as told by opts.
+func PlaceSegments(
+	text string,
+	font *FontRepoEntry,
+	allowedWidth float32,
+	opts TextLayoutOptions,
+) RenderTextResult {
+	rs := []rune(text)
+	t := newLayoutText(rs, []fontRun{{len(rs), font}})
+
+	return placeText(t, font, allowedWidth, opts)
+}
+
+// Places text with runs in any fonts. Lines are as high as font
+// and opts tell, but grow to fit runs in larger fonts.
+func placeText(
+	t *layoutText,
+	font *FontRepoEntry,
+	allowedWidth float32,
+	opts TextLayoutOptions,
+) RenderTextResult {
+	lineHeight := opts.LineHeight
+	if lineHeight <= 0 {
+		lineHeight = font.Metrics.LineHeight()
+	}
+
+	lines := newLineBuilder(t, font, allowedWidth, lineHeight)
+	lines.fill(len(t.rs))
+
+	if opts.MaxLines > 0 && len(lines.rows) > opts.MaxLines {
+		lines = truncate(lines, opts)
+	}
+
+	lines.endLine()
+	lines.align(opts)
+
+	return lines.result()
+}
+
+// Places the pieces of rs[:end] between break opportunities
+func (l *lineBuilder) fill(end int) {
+	rs := l.text.rs
+	breaks := l.text.breaks
+
+	start := 0
+	for i := 1; i <= end; i++ {
+		if breaks[i] == BreakProhibited && i < end {
+			continue
+		}
+
+		// line separators are not drawn
+		stop := i
+		for stop > start && isNewline(rs[stop-1]) {
+			stop -= 1
+		}
+
+		// trailing spaces may hang over the end of the line
+		visible := stop
+		for visible > start && unicode.IsSpace(rs[visible-1]) {
+			visible -= 1
+		}
+
+		if stop > start {
+			l.add(shapeItems(l.text, start, stop), visible)
+		}
+
+		if stop < i {
+			l.newLine(i, false)
+		}
+
+		start = i
+	}
+}
+
+func isNewline(r rune) bool {
+	c := lineBreakClass(r)
+	return c == clsBK || c == clsCR || c == clsLF || c == clsNL
+}
+
+// Places segments line by line
+type lineBuilder struct {
+	text         *layoutText
+	// the line height is taken from, runs in other
+	// fonts may make lines higher
+	font         *FontRepoEntry
+	allowedWidth float32
+	lineHeight   float32
+	baseline     float32
+
+	// advance of the current line, including trailing spaces
+	x float32
+	// the last one is the current line
+	rows []textLine
+
+	width    float32
+	indices  int
+	segments []PlacedSegment
+}
+
+type textLine struct {
+	// first rune of the line
+	start int
+	// first segment of the line
+	segment int
+	// of the top of the line
+	y float32
+	height float32
+	// distance of the baseline from y
+	baseline float32
+	// widest extent of the glyphs, without trailing spaces
+	width float32
+	// whether the line was broken to fit, rather than
+	// at a mandatory break or the end of the text
+	wrapped bool
+	// whether its segments are in visual order already
+	reordered bool
+}
+
+func newLineBuilder(t *layoutText, font *FontRepoEntry, allowedWidth, lineHeight float32) *lineBuilder {
+	baseline := font.Metrics.Baseline(lineHeight)
+
+	return &lineBuilder{
+		text:         t,
+		font:         font,
+		allowedWidth: allowedWidth,
+		lineHeight:   lineHeight,
+		baseline:     baseline,
+		rows:         []textLine{{height: lineHeight, baseline: baseline}},
+	}
+}
+
+func (l *lineBuilder) current() *textLine {
+	return &l.rows[len(l.rows)-1]
+}
+
+// Width of the glyphs shaped from the runes before end
+func advanceOf(segment Segment, end int) float32 {
+	w := float32(0)
+	for _, g := range segment.Glyphs {
+		if g.Cluster < end {
+			w += g.XAdvance
+		}
+	}
+	return w
+}
+
+// Adds a segment, of which only the runes before visibleEnd
+// need to fit into the line
+func (l *lineBuilder) add(segment Segment, visibleEnd int) {
+	visible := advanceOf(segment, visibleEnd)
+
+	if l.x > 0 && l.x+visible > l.allowedWidth {
+		l.newLine(segment.Glyphs[0].Cluster, true)
+	}
+
+	// as a last resort, break between characters
+	for visible > l.allowedWidth {
+		head, tail := splitSegment(segment, l.allowedWidth)
+		if len(head.Glyphs) == 0 || len(tail.Glyphs) == 0 {
+			break
+		}
+
+		l.place(head, head.Width)
+		l.newLine(tail.Glyphs[0].Cluster, true)
+
+		segment = tail
+		visible -= head.Width
+	}
+
+	l.place(segment, visible)
+}
+
+func (l *lineBuilder) place(segment Segment, visible float32) {
+	row := l.current()
+
+	l.segments = append(l.segments, PlacedSegment{
+		Segment:  segment,
+		XOffset:  l.x,
+		YOffset:  row.y,
+		Baseline: row.baseline,
+	})
+	l.grow(segment)
+
+	row.width = max(row.width, l.x+visible)
+	l.width = max(l.width, row.width)
+
+	l.x += segment.Width
+	l.indices += len(segment.Glyphs)
+}
+
+// Makes room in the current line for the glyphs of runs set in
+// other fonts than the line height is taken from. Fallbacks of a
+// font do not count, their glyphs are set like the font's own.
+func (l *lineBuilder) grow(segment Segment) {
+	row := l.current()
+
+	for _, g := range segment.Glyphs {
+		font := l.text.runs[g.Span].Font
+		if font == l.font {
+			continue
+		}
+
+		lineHeight := font.Metrics.LineHeight()
+		above := max(row.baseline, font.Metrics.Baseline(lineHeight))
+		below := max(row.height-row.baseline, lineHeight-font.Metrics.Baseline(lineHeight))
+
+		row.baseline = above
+		row.height = above + below
+	}
+}
+
+// Ends the current line, the next one starts with the rune at next
+func (l *lineBuilder) newLine(next int, wrapped bool) {
+	l.endLine()
+	row := l.current()
+	row.wrapped = wrapped
+
+	l.x = 0
+	l.rows = append(l.rows, textLine{
+		start:    next,
+		segment:  len(l.segments),
+		y:        row.y + row.height,
+		height:   l.lineHeight,
+		baseline: l.baseline,
+	})
+}
+
+// Puts the segments of the current line into visual order
+// and onto the baseline, once all of them are known
+func (l *lineBuilder) endLine() {
+	l.reorder()
+
+	row := l.current()
+	for i := row.segment; i < len(l.segments); i++ {
+		l.segments[i].Baseline = row.baseline
+	}
+}
+
+// Of all lines together
+func (l *lineBuilder) height() float32 {
+	last := l.rows[len(l.rows)-1]
+	return last.y + last.height
+}
+
+// Merges the segments of the current line into one in visual
+// order, if it contains right to left text
+func (l *lineBuilder) reorder() {
+	row := l.current()
+	if row.reordered {
+		return
+	}
+	row.reordered = true
+
+	first := row.segment
+	line := l.segments[first:]
+
+	rtl := false
+	var merged Segment
+	for _, p := range line {
+		for _, g := range p.Segment.Glyphs {
+			rtl = rtl || g.Level%2 == 1
+		}
+		merged.Glyphs = append(merged.Glyphs, p.Segment.Glyphs...)
+		merged.Width += p.Segment.Width
+	}
+
+	if rtl {
+		l.segments = append(l.segments[:first], PlacedSegment{
+			Segment:  ReorderLine(merged),
+			XOffset:  line[0].XOffset,
+			YOffset:  line[0].YOffset,
+			Baseline: line[0].Baseline,
+		})
+	}
+}
+
+func (l *lineBuilder) result() RenderTextResult {
+	return RenderTextResult{
+		Width:          l.width,
+		Height:         l.height(),
+		Indices:        l.indices,
+		PlacedSegments: l.segments,
+	}
+}
+
+// Splits off as many glyphs as fit into width, but at least
+// one cluster. Glyphs of a cluster are kept together.
+func splitSegment(segment Segment, width float32) (Segment, Segment) {
+	glyphs := segment.Glyphs
+	split := 0
+	w := float32(0)
+
+	for i := 0; i < len(glyphs); {
+		j := i + 1
+		clusterWidth := glyphs[i].XAdvance
+		for j < len(glyphs) && glyphs[j].Cluster == glyphs[i].Cluster {
+			clusterWidth += glyphs[j].XAdvance
+			j += 1
+		}
+
+		if split > 0 && w+clusterWidth > width {
+			break
+		}
+
+		w += clusterWidth
+		split = j
+		i = j
+	}
+
+	head := Segment{Glyphs: glyphs[:split], Width: w}
+	tail := Segment{Glyphs: glyphs[split:], Width: segment.Width - w}
+
+	return head, tail
+}
+
+type PlacedSegment struct {
+	Segment Segment
+	// of the top left corner of the line
+	XOffset float32
+	YOffset float32
+	// distance of the baseline from YOffset
+	Baseline float32
+}
+
+type RenderTextResult struct {
+	Height         float32
+	Width          float32
+	Indices        int
+	PlacedSegments []PlacedSegment
+}
+
+// Bounds of the glyph bitmap relative to the pen position
+// on the baseline, with y growing downwards
+func GlyphBounds(metrics *freetype.Metrics) image.Rectangle {
+	x := metrics.HorizontalBearingX
+	y := -metrics.HorizontalBearingY
+
+	return image.Rect(x, y, x+metrics.Width, y+metrics.Height)
+}
+
+// Position of a rasterized glyph relative to the top left corner
+// of the text in pixels. x is the pen position on the baseline,
+// the glyph is moved by its shaped offsets and the bounds of its
+// bitmap, see GlyphBounds.
+func PlaceGlyph(
+	x float32,
+	baseline float32,
+	g Glyph,
+	bounds image.Rectangle,
+) Quad {
+	return Quad{
+		X: x + g.XOffset + float32(bounds.Min.X),
+		Y: baseline - g.YOffset + float32(bounds.Min.Y),
+		W: float32(bounds.Dx()),
+		H: float32(bounds.Dy()),
+	}
+}
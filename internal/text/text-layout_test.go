@@ -1,12 +1,9 @@
-package gl
+package text
 
 import (
-	"dyiui/internal/text"
 	"math"
+	"path/filepath"
 	"testing"
-
-	"github.com/benoitkugler/textlayout/fonts/truetype"
-	"github.com/benoitkugler/textlayout/harfbuzz"
 )
 
 /*
@@ -51,27 +48,18 @@ func TestRenderSegment(t *testing.T) {
 */
 
 
-type TestFont struct {
-	path string
-	ttf *truetype.Font
-	hbFont *harfbuzz.Font
+func testFontsDir() string {
+	return filepath.Join("..", "..", "testdata", "fonts")
 }
 
-func LoadTestFont () TestFont {
-	path := text.GetSomeFont()
-	ttf, err := text.LoadTTF(path)
+func LoadTestFont() *FontRepoEntry {
+	var repo FontRepo
 
-	if err != nil {
+	if _, err := repo.Load(filepath.Join(testFontsDir(), "Go-Regular.ttf")); err != nil {
 		panic(err)
 	}
 
-	hbFont := text.HBFont(ttf)
-
-	return TestFont {
-		path,
-		ttf,
-		hbFont,
-	}
+	return repo.Get()
 }
 
 func StringsShouldEqual(t *testing.T, a string, b string) {
@@ -112,21 +100,18 @@ func TestSegmentation2(t *testing.T) {
 func TestNaiveLineBreaking(t *testing.T) {
 
 	text := "Break inbetween"
-	f := LoadTestFont()
-	face, err := GetFace(f.path, 32.0)
-	if err != nil {
-		panic(err)
-	}
-
+	font := LoadTestFont()
 	lineHeight := float32(32.0)
 
+	// wide enough for each word, but not for both,
+	// narrower lines break the words themselves
+	width := CalculateSegment(font, "inbetween").Width + 1
+
 	placement := PlaceSegments(
 		text,
-		f.ttf,
-		f.hbFont,
-		face,
-		1.0,
-		lineHeight,
+		font,
+		width,
+		TextLayoutOptions{LineHeight: lineHeight},
 	)
 
 	if len(placement.PlacedSegments) != 2 {
@@ -142,9 +127,24 @@ func TestNaiveLineBreaking(t *testing.T) {
 
 	a := float64(placement.Height)
 	b := float64(lineHeight * 2)
-	if math.Abs(a) - math.Abs(b) < .001 {
+	if math.Abs(a - b) > .001 {
 		t.Fatalf("Expected height to be two line heights. Was %f. Expected %f\n", a, b)
 	}
 }
 
 
+
+func TestLineHeightFromFont(t *testing.T) {
+	font := LoadTestFont()
+	placement := PlaceSegments("one\ntwo", font, 1000, TextLayoutOptions{})
+
+	lineHeight := font.Metrics.LineHeight()
+	if math.Abs(float64(placement.Height-2*lineHeight)) > .001 {
+		t.Fatalf("Expected two lines of %f, got a height of %f", lineHeight, placement.Height)
+	}
+
+	second := placement.PlacedSegments[1]
+	if second.YOffset != lineHeight || second.Baseline != font.Metrics.Baseline(lineHeight) {
+		t.Fatalf("Unexpected placement of the second line %+v", second)
+	}
+}
@@ -0,0 +1,320 @@
+package text
+
+import (
+	"unicode"
+)
+
+type TextAlign int
+
+const (
+	AlignLeft TextAlign = iota
+	AlignCenter
+	AlignRight
+	// stretches the spaces of wrapped lines to fill the width,
+	// the last line of a paragraph is left aligned
+	AlignJustify
+)
+
+type VerticalAlign int
+
+const (
+	AlignTop VerticalAlign = iota
+	AlignMiddle
+	AlignBottom
+)
+
+type Ellipsis int
+
+const (
+	// the lines after MaxLines are dropped
+	EllipsisNone Ellipsis = iota
+	// the end of the last line is replaced by an ellipsis
+	EllipsisEnd
+	// the middle of the last line is replaced by an ellipsis,
+	// so that the end of its paragraph stays visible
+	EllipsisMiddle
+)
+
+const ELLIPSIS = "…"
+
+// How PlaceSegments aligns and truncates the lines. The zero
+// value places all lines left aligned from the top.
+type TextLayoutOptions struct {
+	Align         TextAlign
+	VerticalAlign VerticalAlign
+	// size of the box the text is aligned within,
+	// 0 to align within the extent of the text
+	Width  float32
+	Height float32
+	// distance between the baselines of two lines,
+	// 0 for the line height of the font
+	LineHeight float32
+	// lines after which the text is cut off, 0 for no limit
+	MaxLines int
+	Ellipsis Ellipsis
+}
+
+// Lays out the text again, so that it ends after opts.MaxLines lines.
+// With an ellipsis, the last of them is shortened to make room for it.
+func truncate(full *lineBuilder, opts TextLayoutOptions) *lineBuilder {
+	t := full.text
+	rs := t.rs
+	lines := newLineBuilder(t, full.font, full.allowedWidth, full.lineHeight)
+
+	if opts.Ellipsis == EllipsisNone {
+		lines.fill(full.rows[opts.MaxLines].start)
+		lines.rows = lines.rows[:min(len(lines.rows), opts.MaxLines)]
+		return lines
+	}
+
+	last := full.rows[opts.MaxLines-1].start
+	lines.fill(last)
+	if len(lines.rows) < opts.MaxLines {
+		lines.newLine(last, true)
+	}
+
+	// the rest of the paragraph is put into the last line
+	end := last
+	for end < len(rs) && !isNewline(rs[end]) {
+		end += 1
+	}
+
+	visible := end
+	for visible > last && unicode.IsSpace(rs[visible-1]) {
+		visible -= 1
+	}
+
+	content := shapeItems(t, last, end)
+	width := advanceOf(content, visible)
+
+	if width <= lines.allowedWidth && !hasText(rs[end:]) {
+		// only empty lines were cut off
+		lines.place(content, width)
+		return lines
+	}
+
+	shortened := ellipsize(t, content, opts.Ellipsis, lines.allowedWidth)
+	lines.place(shortened, shortened.Width)
+
+	return lines
+}
+
+func hasText(rs []rune) bool {
+	for _, r := range rs {
+		if !unicode.IsSpace(r) {
+			return true
+		}
+	}
+	return false
+}
+
+// Index of the glyph after the cluster, which starts at i
+func nextCluster(glyphs []Glyph, i int) int {
+	j := i + 1
+	for j < len(glyphs) && glyphs[j].Cluster == glyphs[i].Cluster {
+		j += 1
+	}
+	return j
+}
+
+func clusterWidth(glyphs []Glyph) float32 {
+	w := float32(0)
+	for _, g := range glyphs {
+		w += g.XAdvance
+	}
+	return w
+}
+
+// Shortens the content to fit into width together with an ellipsis,
+// which is set in the font of the line's first run. Clusters are kept
+// whole and spaces next to the ellipsis dropped.
+func ellipsize(t *layoutText, content Segment, mode Ellipsis, width float32) Segment {
+	rs := t.rs
+
+	span := 0
+	if len(content.Glyphs) > 0 {
+		span = content.Glyphs[0].Span
+	}
+	font := t.runs[span].Font
+
+	mark := CalculateSegment(font, ELLIPSIS)
+	for _, g := range mark.Glyphs {
+		if g.GID == 0 {
+			mark = CalculateSegment(font, "...")
+			break
+		}
+	}
+
+	glyphs := content.Glyphs
+	available := width - mark.Width
+
+	budget := available
+	if mode == EllipsisMiddle {
+		budget = available / 2
+	}
+
+	head := 0
+	headWidth := float32(0)
+	for head < len(glyphs) {
+		next := nextCluster(glyphs, head)
+		w := clusterWidth(glyphs[head:next])
+		if headWidth+w > budget {
+			break
+		}
+
+		headWidth += w
+		head = next
+	}
+
+	tail := len(glyphs)
+	if mode == EllipsisMiddle {
+		tailWidth := float32(0)
+		for tail > head {
+			prev := tail - 1
+			for prev > head && glyphs[prev-1].Cluster == glyphs[tail-1].Cluster {
+				prev -= 1
+			}
+
+			w := clusterWidth(glyphs[prev:tail])
+			if headWidth+tailWidth+w > available {
+				break
+			}
+
+			tailWidth += w
+			tail = prev
+		}
+
+		for tail < len(glyphs) && unicode.IsSpace(rs[glyphs[tail].Cluster]) {
+			tail += 1
+		}
+	}
+
+	for head > 0 && unicode.IsSpace(rs[glyphs[head-1].Cluster]) {
+		head -= 1
+	}
+
+	// the ellipsis takes the place of the runes it replaces
+	level := 0
+	cluster := 0
+	if head > 0 {
+		level = glyphs[head-1].Level
+	} else if len(glyphs) > 0 {
+		level = glyphs[0].Level
+	}
+	if head < len(glyphs) {
+		cluster = glyphs[head].Cluster
+	}
+
+	var result Segment
+	result.Glyphs = append(result.Glyphs, glyphs[:head]...)
+	for _, g := range mark.Glyphs {
+		g.Level = level
+		g.Cluster = cluster
+		g.Span = span
+		result.Glyphs = append(result.Glyphs, g)
+	}
+	result.Glyphs = append(result.Glyphs, glyphs[tail:]...)
+	result.Width = clusterWidth(result.Glyphs)
+
+	return result
+}
+
+// Moves the lines into place within the box of opts
+func (l *lineBuilder) align(opts TextLayoutOptions) {
+	width := opts.Width
+	if width <= 0 {
+		width = l.width
+	}
+
+	dy := float32(0)
+	if opts.Height > 0 {
+		height := l.height()
+
+		switch opts.VerticalAlign {
+		case AlignMiddle:
+			dy = (opts.Height - height) / 2
+		case AlignBottom:
+			dy = opts.Height - height
+		}
+
+		// text, which does not fit, starts at the top
+		dy = max(dy, 0)
+	}
+
+	for r, row := range l.rows {
+		end := len(l.segments)
+		if r+1 < len(l.rows) {
+			end = l.rows[r+1].segment
+		}
+		segments := l.segments[row.segment:end]
+
+		dx := float32(0)
+		switch opts.Align {
+		case AlignCenter:
+			dx = (width - row.width) / 2
+		case AlignRight:
+			dx = width - row.width
+		case AlignJustify:
+			if row.wrapped {
+				justify(segments, l.text.rs, width-row.width)
+			}
+		}
+
+		for i := range segments {
+			segments[i].XOffset += dx
+			segments[i].YOffset += dy
+		}
+	}
+}
+
+// Distributes the extra width among the spaces between the
+// words of the line. Trailing spaces are not stretched.
+func justify(segments []PlacedSegment, rs []rune, extra float32) {
+	if len(segments) == 0 || extra <= 0 {
+		return
+	}
+
+	// spaces after the last visible rune hang
+	lastVisible := -1
+	for _, p := range segments {
+		for _, g := range p.Segment.Glyphs {
+			if !unicode.IsSpace(rs[g.Cluster]) {
+				lastVisible = max(lastVisible, g.Cluster)
+			}
+		}
+	}
+
+	stretchable := func(g Glyph) bool {
+		return g.Cluster < lastVisible && unicode.IsSpace(rs[g.Cluster])
+	}
+
+	spaces := 0
+	for _, p := range segments {
+		for _, g := range p.Segment.Glyphs {
+			if stretchable(g) {
+				spaces += 1
+			}
+		}
+	}
+
+	if spaces == 0 {
+		return
+	}
+
+	stretch := extra / float32(spaces)
+	x := segments[0].XOffset
+
+	for i := range segments {
+		p := &segments[i]
+		p.XOffset = x
+
+		for j := range p.Segment.Glyphs {
+			if stretchable(p.Segment.Glyphs[j]) {
+				p.Segment.Glyphs[j].XAdvance += stretch
+				p.Segment.Width += stretch
+			}
+		}
+
+		x += p.Segment.Width
+	}
+}
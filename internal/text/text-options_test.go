@@ -0,0 +1,122 @@
+package text
+
+import (
+	"math"
+	"testing"
+)
+
+func lineOffsets(placement RenderTextResult) map[float32]float32 {
+	offsets := map[float32]float32{}
+	for _, p := range placement.PlacedSegments {
+		if x, ok := offsets[p.YOffset]; !ok || p.XOffset < x {
+			offsets[p.YOffset] = p.XOffset
+		}
+	}
+	return offsets
+}
+
+func nearly(a, b float32) bool {
+	return math.Abs(float64(a-b)) < .01
+}
+
+func TestAlignCenterAndRight(t *testing.T) {
+	font := LoadTestFont()
+	long := CalculateSegment(font, "longer").Width
+	short := CalculateSegment(font, "ab").Width
+
+	center := PlaceSegments("longer\nab", font, 1000, TextLayoutOptions{LineHeight: 32, Align: AlignCenter})
+	if x := lineOffsets(center)[32]; !nearly(x, (long-short)/2) {
+		t.Fatalf("Expected the short line to be centered at %f, got %f", (long-short)/2, x)
+	}
+
+	right := PlaceSegments("ab", font, 1000, TextLayoutOptions{LineHeight: 32, Align: AlignRight, Width: 100})
+	if x := lineOffsets(right)[0]; !nearly(x, 100-short) {
+		t.Fatalf("Expected the line to end at the right of the box, starts at %f", x)
+	}
+}
+
+func TestJustifyStretchesWrappedLines(t *testing.T) {
+	font := LoadTestFont()
+	width := CalculateSegment(font, "aaa bb cccc").Width
+
+	placement := PlaceSegments("aaa b cccc dd", font, width, TextLayoutOptions{LineHeight: 32, Align: AlignJustify})
+
+	var first, last PlacedSegment
+	for _, p := range placement.PlacedSegments {
+		if p.YOffset == 0 {
+			first = p
+		} else {
+			last = p
+		}
+	}
+
+	end := first.XOffset + advanceOf(first.Segment, 10)
+	if !nearly(end, placement.Width) {
+		t.Fatalf("Expected the wrapped line to end at %f, got %f", placement.Width, end)
+	}
+
+	if last.XOffset != 0 {
+		t.Fatalf("Expected the last line to stay left aligned, got %f", last.XOffset)
+	}
+}
+
+func TestVerticalAlign(t *testing.T) {
+	font := LoadTestFont()
+
+	placement := PlaceSegments("a", font, 1000, TextLayoutOptions{LineHeight: 32, VerticalAlign: AlignMiddle, Height: 100})
+	if y := placement.PlacedSegments[0].YOffset; y != 34 {
+		t.Fatalf("Expected the line to be moved down by 34, got %f", y)
+	}
+}
+
+func TestMaxLinesWithoutEllipsis(t *testing.T) {
+	font := LoadTestFont()
+
+	placement := PlaceSegments("one\ntwo\nthree", font, 1000, TextLayoutOptions{LineHeight: 32, MaxLines: 2})
+	if placement.Height != 64 || len(placement.PlacedSegments) != 2 {
+		t.Fatalf("Expected two lines, got %d segments of height %f", len(placement.PlacedSegments), placement.Height)
+	}
+}
+
+func TestEllipsis(t *testing.T) {
+	font := LoadTestFont()
+	text := "abcdefghijklmnopqrstuvwxyz"
+	width := CalculateSegment(font, text).Width / 2
+	mark := CalculateSegment(font, ELLIPSIS).Glyphs[0].GID
+
+	for _, mode := range []Ellipsis{EllipsisEnd, EllipsisMiddle} {
+		placement := PlaceSegments(text, font, width, TextLayoutOptions{LineHeight: 32, MaxLines: 1, Ellipsis: mode})
+
+		if placement.Height != 32 || len(placement.PlacedSegments) != 1 {
+			t.Fatalf("Expected a single line, got %d segments", len(placement.PlacedSegments))
+		}
+
+		segment := placement.PlacedSegments[0].Segment
+		if segment.Width > width {
+			t.Fatalf("Expected the line to fit into %f, got %f", width, segment.Width)
+		}
+
+		glyphs := segment.Glyphs
+		last := glyphs[len(glyphs)-1]
+
+		switch mode {
+		case EllipsisEnd:
+			if last.GID != mark || glyphs[0].Cluster != 0 {
+				t.Fatalf("Expected the ellipsis at the end, got %+v", last)
+			}
+		case EllipsisMiddle:
+			if last.Cluster != len(text)-1 || glyphs[0].Cluster != 0 {
+				t.Fatalf("Expected the start and end of the text to be kept, got %+v", glyphs)
+			}
+		}
+	}
+}
+
+func TestNoEllipsisForFittingText(t *testing.T) {
+	font := LoadTestFont()
+
+	placement := PlaceSegments("short\n", font, 1000, TextLayoutOptions{LineHeight: 32, MaxLines: 1, Ellipsis: EllipsisEnd})
+	if len(placement.PlacedSegments[0].Segment.Glyphs) != 5 {
+		t.Fatalf("Expected the text to be kept as it is, got %+v", placement.PlacedSegments[0].Segment.Glyphs)
+	}
+}
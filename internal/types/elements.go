@@ -2,6 +2,7 @@ package types
 
 type Float = float32
 type Pos = int
+type ElementId = uint32
 
 type Quad struct {
     X Float
@@ -18,3 +19,26 @@ func NewQuad(x,y,w,h Float) Quad {
         H: h,
     }
 }
+
+// Area both quads cover, which has no size, if they do not overlap
+func (q Quad) Intersect(o Quad) Quad {
+    if o.Contains(q) {
+        return q
+    }
+    if q.Contains(o) {
+        return o
+    }
+
+    x0 := max(q.X, o.X)
+    y0 := max(q.Y, o.Y)
+    x1 := min(q.X + q.W, o.X + o.W)
+    y1 := min(q.Y + q.H, o.Y + o.H)
+
+    return NewQuad(x0, y0, max(x1 - x0, 0), max(y1 - y0, 0))
+}
+
+// Whether o lies within q
+func (q Quad) Contains(o Quad) bool {
+    return q.X <= o.X && q.Y <= o.Y &&
+        o.X + o.W <= q.X + q.W && o.Y + o.H <= q.Y + q.H
+}
@@ -9,14 +9,47 @@ type Context struct {
 	Height uint32
 
     PointerState PointerState
+    Keyboard KeyboardState
+
+    // hot and active widgets
+    Interaction Interaction
+    // widget receiving keyboard input, 0 if none
+    FocusedId ElementId
+    TextInput TextInputState
+
+    // of the widgets, by their id, see GetState
+    State StateStore
 }
 
+// Mouse input of the current frame. Like the keyboard, events are
+// queued and only change the state, when NewFrame is called.
 type PointerState struct {
+    // of the left button, see Buttons for all of them
     Active bool
     JustActivated bool
     JustReleased bool
     PosX Float
     PosY Float
+    // moved during the last frame
+    DeltaX Float
+    DeltaY Float
+    Buttons [MOUSE_BUTTON_COUNT]ButtonState
+    // the pointer left the window, nothing is within reach
+    Outside bool
+    JustEntered bool
+    JustLeft bool
+    // scrolled during this frame in steps of the wheel,
+    // positive when scrolling up or to the left. Widgets,
+    // which scroll, set them to 0, so nothing else does.
+    WheelX Float
+    WheelY Float
+    // IsWithin reports nothing outside of it, nil if
+    // unrestricted. The UI keeps it in sync with its clip rect.
+    Clip *Quad
+
+    queuedWheelX Float
+    queuedWheelY Float
+    queuedEvents []mouseEvent
 }
 
 func NewPointerState() PointerState {
@@ -29,7 +62,26 @@ func NewPointerState() PointerState {
     }
 }
 
+// Scrolling arrives through a callback at any time, it is
+// summed up and applied with the beginning of the next frame
+func (s *PointerState) QueueWheel(dx, dy Float) {
+    s.queuedWheelX += dx
+    s.queuedWheelY += dy
+}
+
+// Whether the pointer is over the rect, but not
+// over a part of it, which is clipped away
 func (s *PointerState) IsWithin(x, y, w, h Float) bool {
+    if s.Outside {
+        return false
+    }
+    if c := s.Clip; c != nil && !isWithin(s, c.X, c.Y, c.W, c.H) {
+        return false
+    }
+    return isWithin(s, x, y, w, h)
+}
+
+func isWithin(s *PointerState, x, y, w, h Float) bool {
     return x <= s.PosX && s.PosX <= (x + w) &&
         y <= s.PosY && s.PosY <= (y + h)
 }
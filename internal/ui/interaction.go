@@ -0,0 +1,122 @@
+package ui
+
+import (
+    . "dyiui/internal/types"
+)
+
+// Who gets the pointer. Widgets are hot, while the pointer is over
+// them and nothing else is on top, and active, while they hold on
+// to a press of the pointer. Keyboard focus is kept apart in
+// FocusedId, it does not move, when the pointer does.
+type Interaction struct {
+    // topmost widget under the pointer, 0 if none
+    HotId ElementId
+    // widget, which captured the press of the pointer, until
+    // it is released, 0 if none. No other widget becomes hot
+    // meanwhile.
+    ActiveId ElementId
+
+    // last widget under the pointer in this frame, which is
+    // drawn on top of those submitted before
+    topmostId ElementId
+    topmostRect Quad
+    // topmost widget of the last frame, which is hot before
+    // anything else, while the pointer stays over it
+    lastTopmostId ElementId
+    // where the widgets were submitted, by their id. Widgets made
+    // of several rects are known by the first one.
+    rects map[ElementId]Quad
+    lastRects map[ElementId]Quad
+}
+
+// Begins a frame of interaction. Since widgets are submitted
+// bottom to top, which one ends up on top is only known at the
+// end of a frame. It gets precedence during the next one.
+func (c *Context) NewFrame() {
+    c.State.NextFrame()
+
+    in := &c.Interaction
+    p := &c.PointerState
+    r := in.topmostRect
+
+    in.lastTopmostId = 0
+    if in.topmostId != 0 && isWithin(p, r.X, r.Y, r.W, r.H) {
+        in.lastTopmostId = in.topmostId
+    }
+
+    in.topmostId = 0
+    in.HotId = 0
+
+    in.rects, in.lastRects = in.lastRects, in.rects
+    clear(in.rects)
+
+    // the active widget was not submitted, when the pointer was released
+    if !p.Active && !p.JustReleased {
+        in.ActiveId = 0
+    }
+}
+
+// Registers the widget at the given rect. Returns true, if it is
+// hot, which only the topmost of the widgets under the pointer is.
+// Widgets made of several rects may call it once per rect.
+func (c *Context) Hover(id ElementId, rect Quad) bool {
+    in := &c.Interaction
+
+    if in.rects == nil {
+        in.rects = map[ElementId]Quad{}
+    }
+    if _, ok := in.rects[id]; !ok {
+        in.rects[id] = rect
+    }
+
+    // The topmost widget of the last frame gives up its precedence,
+    // once it is resubmitted elsewhere, e.g. since it was scrolled.
+    // Widgets submitted before it can only tell from the next frame.
+    if id == in.lastTopmostId && in.lastRects[id] != in.rects[id] {
+        in.lastTopmostId = 0
+    }
+
+    if !c.PointerState.IsWithin(rect.X, rect.Y, rect.W, rect.H) {
+        // moved away from under the pointer
+        if id == in.lastTopmostId {
+            in.lastTopmostId = 0
+        }
+        return false
+    }
+
+    in.topmostId = id
+    in.topmostRect = rect
+
+    covered := in.lastTopmostId != 0 && in.lastTopmostId != id
+    eligible := (in.ActiveId == 0 || in.ActiveId == id) && !covered
+
+    if in.HotId == 0 && eligible {
+        in.HotId = id
+    }
+
+    return in.HotId == id
+}
+
+// Press and release of a clickable widget, which is hovered as
+// told by Hover. A press over the widget makes it active and it is
+// clicked, when the pointer is released over it while it is active.
+// Returns whether it is held down and whether it was clicked.
+func (c *Context) ClickBehavior(id ElementId, hovered bool) (bool, bool) {
+    in := &c.Interaction
+    p := &c.PointerState
+
+    if hovered && p.JustActivated {
+        in.ActiveId = id
+    }
+
+    if in.ActiveId != id {
+        return false, false
+    }
+
+    if p.Active {
+        return true, false
+    }
+
+    in.ActiveId = 0
+    return false, hovered && p.JustReleased
+}
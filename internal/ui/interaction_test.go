@@ -0,0 +1,115 @@
+package ui
+
+import (
+    . "dyiui/internal/types"
+    "testing"
+)
+
+func pointerAt(c *Context, x, y Float, active, justActivated, justReleased bool) {
+    c.PointerState = NewPointerState()
+    c.PointerState.PosX, c.PointerState.PosY = x, y
+    c.PointerState.Active = active
+    c.PointerState.JustActivated = justActivated
+    c.PointerState.JustReleased = justReleased
+    c.NewFrame()
+}
+
+func TestOnlyTheTopmostWidgetIsHot(t *testing.T) {
+    c := &Context{}
+    rect := NewQuad(0, 0, 100, 100)
+
+    pointerAt(c, 10, 10, false, false, false)
+    c.Hover(1, rect)
+    c.Hover(2, rect)
+
+    pointerAt(c, 10, 10, false, false, false)
+    if c.Hover(1, rect) || !c.Hover(2, rect) {
+        t.Fatalf("Expected only the widget submitted last to be hot")
+    }
+}
+
+func TestClickCompletesOnReleaseOverTheSameWidget(t *testing.T) {
+    c := &Context{}
+    a := NewQuad(0, 0, 50, 50)
+    b := NewQuad(50, 0, 50, 50)
+
+    frame := func() (bool, bool) {
+        _, clickedA := c.ClickBehavior(1, c.Hover(1, a))
+        _, clickedB := c.ClickBehavior(2, c.Hover(2, b))
+        return clickedA, clickedB
+    }
+
+    pointerAt(c, 10, 10, true, true, false)
+    frame()
+    if c.Interaction.ActiveId != 1 {
+        t.Fatalf("Expected the pressed widget to become active")
+    }
+
+    // dragged over the other widget, which does not become hot
+    pointerAt(c, 60, 10, true, false, false)
+    frame()
+    if c.Interaction.HotId != 0 {
+        t.Fatalf("Expected no other widget to be hot, while one is active")
+    }
+
+    pointerAt(c, 60, 10, false, false, true)
+    if clickedA, clickedB := frame(); clickedA || clickedB {
+        t.Fatalf("Expected no click, when released over another widget")
+    }
+
+    pointerAt(c, 10, 10, true, true, false)
+    frame()
+    pointerAt(c, 10, 10, false, false, true)
+    if clickedA, _ := frame(); !clickedA || c.Interaction.ActiveId != 0 {
+        t.Fatalf("Expected a click on release over the pressed widget")
+    }
+}
+
+func TestWidgetsMovedUnderThePointerBecomeHot(t *testing.T) {
+    c := &Context{}
+    rows := func(offset Float) (bool, bool) {
+        a := c.Hover(1, NewQuad(0, offset, 100, 20))
+        b := c.Hover(2, NewQuad(0, offset + 20, 100, 20))
+        return a, b
+    }
+
+    pointerAt(c, 10, 30, false, false, false)
+    rows(0)
+
+    // scrolled up, the first row comes under the pointer, but
+    // the second one, which moved away, is only submitted after it
+    pointerAt(c, 10, 30, false, false, false)
+    if a, b := rows(20); a || b {
+        t.Fatalf("Expected no row to be hot, while it is not known, which is on top")
+    }
+
+    pointerAt(c, 10, 30, false, false, false)
+    if a, b := rows(20); !a || b {
+        t.Fatalf("Expected the first row to be hot a frame after scrolling up")
+    }
+
+    // scrolled down, the first row moves away before the second comes
+    pointerAt(c, 10, 30, false, false, false)
+    if a, b := rows(0); a || !b {
+        t.Fatalf("Expected the second row to be hot right after scrolling down")
+    }
+}
+
+func TestMovingWidgetsStayBelowAnOverlay(t *testing.T) {
+    c := &Context{}
+    overlay := NewQuad(0, 0, 100, 100)
+
+    pointerAt(c, 10, 10, false, false, false)
+    c.Hover(1, NewQuad(0, 0, 50, 50))
+    c.Hover(2, overlay)
+
+    for y := Float(2); y < 6; y += 2 {
+        pointerAt(c, 10, 10, false, false, false)
+        below := c.Hover(1, NewQuad(0, y, 50, 50))
+        above := c.Hover(2, overlay)
+
+        if below || !above {
+            t.Fatalf("Expected the overlay to stay hot over the moving widget")
+        }
+    }
+}
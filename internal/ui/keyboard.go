@@ -0,0 +1,230 @@
+package ui
+
+// Keys are independent of the windowing library,
+// the app maps its key codes onto these
+type Key = int
+
+const (
+    KeyUnknown Key = iota
+
+    KeySpace
+    KeyEnter
+    KeyTab
+    KeyBackspace
+    KeyDelete
+    KeyInsert
+    KeyEscape
+
+    KeyLeft
+    KeyRight
+    KeyUp
+    KeyDown
+    KeyHome
+    KeyEnd
+    KeyPageUp
+    KeyPageDown
+
+    // left and right keys are kept apart, so releasing
+    // one of them keeps the modifier of the other
+    KeyLeftShift
+    KeyRightShift
+    KeyLeftControl
+    KeyRightControl
+    KeyLeftAlt
+    KeyRightAlt
+    KeyLeftSuper
+    KeyRightSuper
+
+    Key0
+    Key1
+    Key2
+    Key3
+    Key4
+    Key5
+    Key6
+    Key7
+    Key8
+    Key9
+
+    KeyA
+    KeyB
+    KeyC
+    KeyD
+    KeyE
+    KeyF
+    KeyG
+    KeyH
+    KeyI
+    KeyJ
+    KeyK
+    KeyL
+    KeyM
+    KeyN
+    KeyO
+    KeyP
+    KeyQ
+    KeyR
+    KeyS
+    KeyT
+    KeyU
+    KeyV
+    KeyW
+    KeyX
+    KeyY
+    KeyZ
+
+    KeyF1
+    KeyF2
+    KeyF3
+    KeyF4
+    KeyF5
+    KeyF6
+    KeyF7
+    KeyF8
+    KeyF9
+    KeyF10
+    KeyF11
+    KeyF12
+
+    KEY_COUNT
+)
+
+type Modifiers = int
+
+const (
+    ModShift Modifiers = 1 << iota
+    ModControl
+    ModAlt
+    ModSuper
+)
+
+type KeyAction = int
+
+const (
+    KeyRelease KeyAction = iota
+    KeyPress
+    KeyRepeat
+)
+
+type KeyEvent struct {
+    Key Key
+    Action KeyAction
+    Modifiers Modifiers
+}
+
+// Keyboard input of the current frame.
+// Events arrive through callbacks at any time and are queued,
+// so the state only changes between frames, when NewFrame is called.
+type KeyboardState struct {
+    // modifier keys held
+    Modifiers Modifiers
+    // text typed during the last frame, in order
+    Runes []rune
+    // key events of the last frame, in order
+    Events []KeyEvent
+
+    down [KEY_COUNT]bool
+    pressed [KEY_COUNT]bool
+    released [KEY_COUNT]bool
+    repeated [KEY_COUNT]bool
+
+    queuedEvents []KeyEvent
+    queuedRunes []rune
+}
+
+func NewKeyboardState() KeyboardState {
+    return KeyboardState{}
+}
+
+// Queues a key event, to be applied with the next frame
+func (k *KeyboardState) QueueKey(key Key, action KeyAction, mods Modifiers) {
+    k.queuedEvents = append(k.queuedEvents, KeyEvent {
+        Key: key,
+        Action: action,
+        Modifiers: mods,
+    })
+}
+
+// Queues a typed character, to be applied with the next frame
+func (k *KeyboardState) QueueRune(r rune) {
+    k.queuedRunes = append(k.queuedRunes, r)
+}
+
+// Applies the events queued since the last call
+func (k *KeyboardState) NewFrame() {
+    k.pressed = [KEY_COUNT]bool{}
+    k.released = [KEY_COUNT]bool{}
+    k.repeated = [KEY_COUNT]bool{}
+
+    k.Events = append(k.Events[:0], k.queuedEvents...)
+    k.Runes = append(k.Runes[:0], k.queuedRunes...)
+    k.queuedEvents = k.queuedEvents[:0]
+    k.queuedRunes = k.queuedRunes[:0]
+
+    for _, e := range k.Events {
+        if e.Key < 0 || e.Key >= KEY_COUNT {
+            continue
+        }
+
+        switch e.Action {
+        case KeyPress:
+            k.pressed[e.Key] = !k.down[e.Key]
+            k.down[e.Key] = true
+        case KeyRepeat:
+            k.repeated[e.Key] = true
+            k.down[e.Key] = true
+        case KeyRelease:
+            k.released[e.Key] = k.down[e.Key]
+            k.down[e.Key] = false
+        }
+    }
+
+    // derived from the keys rather than the events' modifiers,
+    // since some platforms report the state before the event
+    k.Modifiers = 0
+    for key, mod := range modifierKeys {
+        if k.down[key] {
+            k.Modifiers |= mod
+        }
+    }
+}
+
+var modifierKeys = map[Key]Modifiers {
+    KeyLeftShift: ModShift,
+    KeyRightShift: ModShift,
+    KeyLeftControl: ModControl,
+    KeyRightControl: ModControl,
+    KeyLeftAlt: ModAlt,
+    KeyRightAlt: ModAlt,
+    KeyLeftSuper: ModSuper,
+    KeyRightSuper: ModSuper,
+}
+
+// Key is held
+func (k *KeyboardState) IsDown(key Key) bool {
+    return k.down[key]
+}
+
+// Key went down during the last frame
+func (k *KeyboardState) IsPressed(key Key) bool {
+    return k.pressed[key]
+}
+
+// Key went up during the last frame
+func (k *KeyboardState) IsReleased(key Key) bool {
+    return k.released[key]
+}
+
+// Key is held long enough, that the system repeats it
+func (k *KeyboardState) IsRepeated(key Key) bool {
+    return k.repeated[key]
+}
+
+// What text editing reacts to
+func (k *KeyboardState) IsPressedOrRepeated(key Key) bool {
+    return k.pressed[key] || k.repeated[key]
+}
+
+func (k *KeyboardState) HasModifiers(mods Modifiers) bool {
+    return k.Modifiers & mods == mods
+}
@@ -0,0 +1,92 @@
+package ui
+
+import (
+    "testing"
+)
+
+func TestKeyStatesAcrossFrames(t *testing.T) {
+    k := NewKeyboardState()
+
+    k.QueueKey(KeyA, KeyPress, 0)
+    if k.IsDown(KeyA) {
+        t.Fatalf("Queued events should only apply with the next frame")
+    }
+
+    k.NewFrame()
+    if !k.IsDown(KeyA) || !k.IsPressed(KeyA) {
+        t.Fatalf("Expected key to be down and pressed")
+    }
+
+    k.QueueKey(KeyA, KeyRepeat, 0)
+    k.NewFrame()
+    if !k.IsDown(KeyA) || k.IsPressed(KeyA) || !k.IsRepeated(KeyA) {
+        t.Fatalf("Expected key to be down and repeated, but not pressed again")
+    }
+
+    k.NewFrame()
+    if !k.IsDown(KeyA) || k.IsRepeated(KeyA) {
+        t.Fatalf("Expected key to stay down without events")
+    }
+
+    k.QueueKey(KeyA, KeyRelease, 0)
+    k.NewFrame()
+    if k.IsDown(KeyA) || !k.IsReleased(KeyA) {
+        t.Fatalf("Expected key to be released")
+    }
+}
+
+func TestModifiersFollowModifierKeys(t *testing.T) {
+    k := NewKeyboardState()
+
+    k.QueueKey(KeyLeftShift, KeyPress, 0)
+    k.QueueKey(KeyLeftControl, KeyPress, ModShift)
+    k.NewFrame()
+
+    if !k.HasModifiers(ModShift | ModControl) {
+        t.Fatalf("Expected shift and control to be held, got %b", k.Modifiers)
+    }
+
+    k.QueueKey(KeyLeftShift, KeyRelease, ModShift | ModControl)
+    k.NewFrame()
+
+    if k.Modifiers != ModControl {
+        t.Fatalf("Expected only control to be held, got %b", k.Modifiers)
+    }
+}
+
+func TestModifiersStayWhileTheOtherKeyIsHeld(t *testing.T) {
+    k := NewKeyboardState()
+
+    k.QueueKey(KeyLeftShift, KeyPress, 0)
+    k.QueueKey(KeyRightShift, KeyPress, ModShift)
+    k.QueueKey(KeyLeftShift, KeyRelease, ModShift)
+    k.NewFrame()
+
+    if !k.HasModifiers(ModShift) {
+        t.Fatalf("Expected shift to be held by the right key")
+    }
+
+    k.QueueKey(KeyRightShift, KeyRelease, ModShift)
+    k.NewFrame()
+
+    if k.Modifiers != 0 {
+        t.Fatalf("Expected no modifiers to be held, got %b", k.Modifiers)
+    }
+}
+
+func TestRunesAreCollectedPerFrame(t *testing.T) {
+    k := NewKeyboardState()
+
+    k.QueueRune('h')
+    k.QueueRune('i')
+    k.NewFrame()
+
+    if string(k.Runes) != "hi" {
+        t.Fatalf("Expected typed runes of the frame, got '%s'", string(k.Runes))
+    }
+
+    k.NewFrame()
+    if len(k.Runes) != 0 {
+        t.Fatalf("Expected runes to be cleared with the next frame")
+    }
+}
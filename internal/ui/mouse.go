@@ -0,0 +1,209 @@
+package ui
+
+import (
+    . "dyiui/internal/types"
+    "time"
+)
+
+type MouseButton = int
+
+const (
+    MouseLeft MouseButton = iota
+    MouseRight
+    MouseMiddle
+    MouseExtra1
+    MouseExtra2
+
+    MOUSE_BUTTON_COUNT
+)
+
+// Presses within this time and distance of the one before
+// count as double and triple clicks
+const DOUBLE_CLICK_TIME = 400 * time.Millisecond
+const DOUBLE_CLICK_DISTANCE = 6.0
+// How far the pointer has to move away from where a button was
+// pressed, before it is dragged, so shaky clicks stay clicks
+const DRAG_THRESHOLD = 4.0
+
+type ButtonState struct {
+    Down bool
+    // went down or up during the last frame, both of which
+    // happen within a single frame on quick clicks
+    Pressed bool
+    Released bool
+    // presses in quick succession, 2 on a double click and
+    // 3 on a triple click. Kept until the next press.
+    Clicks int
+    PressX Float
+    PressY Float
+    PressTime time.Time
+    // moved further than DRAG_THRESHOLD since the press. It is
+    // still set during the frame, in which the button is released.
+    Dragging bool
+    // from where the button was pressed to the pointer, while dragging
+    DragX Float
+    DragY Float
+}
+
+type mouseEventKind int
+
+const (
+    mouseMove mouseEventKind = iota
+    mouseButton
+    mouseEnter
+    mouseLeave
+)
+
+type mouseEvent struct {
+    kind mouseEventKind
+    button MouseButton
+    down bool
+    x Float
+    y Float
+    at time.Time
+}
+
+// Queues the pointer moving to x, y, to be applied with the next frame
+func (s *PointerState) QueueMove(x, y Float) {
+    s.queuedEvents = append(s.queuedEvents, mouseEvent { kind: mouseMove, x: x, y: y })
+}
+
+// Queues a button going down or up at the given time,
+// which click counts are based on
+func (s *PointerState) QueueButton(button MouseButton, down bool, at time.Time) {
+    s.queuedEvents = append(s.queuedEvents, mouseEvent { kind: mouseButton, button: button, down: down, at: at })
+}
+
+// Queues the pointer entering or leaving the window
+func (s *PointerState) QueueEnter(entered bool) {
+    kind := mouseLeave
+    if entered {
+        kind = mouseEnter
+    }
+    s.queuedEvents = append(s.queuedEvents, mouseEvent { kind: kind })
+}
+
+// Applies the events and scrolling queued since the last call.
+// The left button is what Active, JustActivated and JustReleased
+// tell about.
+func (s *PointerState) NewFrame() {
+    s.WheelX, s.WheelY = s.queuedWheelX, s.queuedWheelY
+    s.queuedWheelX, s.queuedWheelY = 0, 0
+
+    s.JustEntered, s.JustLeft = false, false
+    for i := range s.Buttons {
+        b := &s.Buttons[i]
+        b.Pressed, b.Released = false, false
+        if !b.Down {
+            b.Dragging = false
+            b.DragX, b.DragY = 0, 0
+        }
+    }
+
+    x, y := s.PosX, s.PosY
+
+    for _, e := range s.queuedEvents {
+        switch e.kind {
+        case mouseMove:
+            s.PosX, s.PosY = e.x, e.y
+        case mouseEnter:
+            s.Outside = false
+            s.JustEntered = true
+        case mouseLeave:
+            s.Outside = true
+            s.JustLeft = true
+        case mouseButton:
+            if e.button < 0 || e.button >= MOUSE_BUTTON_COUNT {
+                continue
+            }
+            if e.down {
+                s.press(&s.Buttons[e.button], e.at)
+            } else {
+                s.release(&s.Buttons[e.button])
+            }
+        }
+    }
+    s.queuedEvents = s.queuedEvents[:0]
+
+    s.DeltaX, s.DeltaY = s.PosX - x, s.PosY - y
+
+    for i := range s.Buttons {
+        b := &s.Buttons[i]
+        if !b.Down && !b.Released {
+            continue
+        }
+
+        dx, dy := s.PosX - b.PressX, s.PosY - b.PressY
+        if dx * dx + dy * dy > DRAG_THRESHOLD * DRAG_THRESHOLD {
+            b.Dragging = true
+        }
+        if b.Dragging {
+            b.DragX, b.DragY = dx, dy
+        }
+    }
+
+    left := &s.Buttons[MouseLeft]
+    s.Active = left.Down
+    s.JustActivated = left.Pressed
+    s.JustReleased = left.Released
+}
+
+func (s *PointerState) press(b *ButtonState, at time.Time) {
+    if b.Down {
+        return
+    }
+
+    dx, dy := s.PosX - b.PressX, s.PosY - b.PressY
+    near := dx * dx + dy * dy <= DOUBLE_CLICK_DISTANCE * DOUBLE_CLICK_DISTANCE
+    if b.Clicks > 0 && near && at.Sub(b.PressTime) <= DOUBLE_CLICK_TIME {
+        b.Clicks += 1
+    } else {
+        b.Clicks = 1
+    }
+
+    b.Down = true
+    b.Pressed = true
+    b.PressX, b.PressY = s.PosX, s.PosY
+    b.PressTime = at
+    b.Dragging = false
+    b.DragX, b.DragY = 0, 0
+}
+
+func (s *PointerState) release(b *ButtonState) {
+    b.Released = b.Down
+    b.Down = false
+}
+
+// Button is held
+func (s *PointerState) IsDown(button MouseButton) bool {
+    return s.Buttons[button].Down
+}
+
+// Button went down during the last frame
+func (s *PointerState) IsPressed(button MouseButton) bool {
+    return s.Buttons[button].Pressed
+}
+
+// Button went up during the last frame
+func (s *PointerState) IsReleased(button MouseButton) bool {
+    return s.Buttons[button].Released
+}
+
+// Button went down the second time in quick succession
+func (s *PointerState) IsDoubleClicked(button MouseButton) bool {
+    b := &s.Buttons[button]
+    return b.Pressed && b.Clicks == 2
+}
+
+// Button went down the third time in quick succession
+func (s *PointerState) IsTripleClicked(button MouseButton) bool {
+    b := &s.Buttons[button]
+    return b.Pressed && b.Clicks == 3
+}
+
+// Distance the pointer was dragged with the button held,
+// 0 until it left the dead zone around the press
+func (s *PointerState) DragDelta(button MouseButton) (Float, Float) {
+    b := &s.Buttons[button]
+    return b.DragX, b.DragY
+}
@@ -0,0 +1,94 @@
+package ui
+
+import (
+    "testing"
+    "time"
+)
+
+func TestQuickPressesCountAsDoubleAndTripleClicks(t *testing.T) {
+    s := NewPointerState()
+    start := time.Now()
+
+    click := func(after time.Duration) {
+        s.QueueButton(MouseLeft, true, start.Add(after))
+        s.QueueButton(MouseLeft, false, start.Add(after))
+        s.NewFrame()
+    }
+
+    click(0)
+    if s.Buttons[MouseLeft].Clicks != 1 || !s.JustActivated || !s.JustReleased || s.Active {
+        t.Fatalf("Expected a single click within one frame, got %+v", s.Buttons[MouseLeft])
+    }
+
+    click(100 * time.Millisecond)
+    if !s.IsDoubleClicked(MouseLeft) {
+        t.Fatalf("Expected a double click, got %+v", s.Buttons[MouseLeft])
+    }
+
+    click(200 * time.Millisecond)
+    if !s.IsTripleClicked(MouseLeft) {
+        t.Fatalf("Expected a triple click, got %+v", s.Buttons[MouseLeft])
+    }
+
+    click(time.Second)
+    if s.Buttons[MouseLeft].Clicks != 1 {
+        t.Fatalf("Expected a slow click to count again from 1, got %+v", s.Buttons[MouseLeft])
+    }
+
+    s.QueueMove(50, 50)
+    click(time.Second + 100 * time.Millisecond)
+    if s.Buttons[MouseLeft].Clicks != 1 {
+        t.Fatalf("Expected a click elsewhere to count again from 1, got %+v", s.Buttons[MouseLeft])
+    }
+}
+
+func TestDragStartsOutsideTheDeadZone(t *testing.T) {
+    s := NewPointerState()
+
+    s.QueueMove(10, 10)
+    s.QueueButton(MouseRight, true, time.Now())
+    s.NewFrame()
+
+    s.QueueMove(12, 11)
+    s.NewFrame()
+    if x, y := s.DragDelta(MouseRight); s.Buttons[MouseRight].Dragging || x != 0 || y != 0 {
+        t.Fatalf("Expected no drag within the dead zone, got %+v", s.Buttons[MouseRight])
+    }
+
+    s.QueueMove(30, 5)
+    s.NewFrame()
+    if x, y := s.DragDelta(MouseRight); x != 20 || y != -5 || s.DeltaX != 18 {
+        t.Fatalf("Expected to be dragged by 20, -5, got %f, %f", x, y)
+    }
+
+    if s.Active || s.IsDown(MouseLeft) {
+        t.Fatalf("Expected the left button to be unaffected")
+    }
+
+    s.QueueButton(MouseRight, false, time.Now())
+    s.NewFrame()
+    if !s.IsReleased(MouseRight) || !s.Buttons[MouseRight].Dragging {
+        t.Fatalf("Expected the drag to be reported with the release")
+    }
+
+    s.NewFrame()
+    if s.Buttons[MouseRight].Dragging {
+        t.Fatalf("Expected the drag to end after the release")
+    }
+}
+
+func TestNothingIsWithinReachOutsideOfTheWindow(t *testing.T) {
+    s := NewPointerState()
+
+    s.QueueEnter(false)
+    s.NewFrame()
+    if !s.JustLeft || s.IsWithin(-10, -10, 100, 100) {
+        t.Fatalf("Expected the pointer to have left")
+    }
+
+    s.QueueEnter(true)
+    s.NewFrame()
+    if !s.JustEntered || !s.IsWithin(-10, -10, 100, 100) {
+        t.Fatalf("Expected the pointer to be back")
+    }
+}
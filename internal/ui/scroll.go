@@ -0,0 +1,33 @@
+package ui
+
+import (
+    . "dyiui/internal/types"
+)
+
+type ScrollAxis = int
+
+const (
+    NoAxis ScrollAxis = iota
+    Horizontal
+    Vertical
+)
+
+// How far a scroll area is scrolled, kept across frames
+type ScrollState struct {
+    // of the content, in pixels
+    OffsetX Float
+    OffsetY Float
+    // of the content in the last frame
+    ContentWidth Float
+    ContentHeight Float
+    // scrollbar, of which the thumb is being dragged
+    Dragging ScrollAxis
+    // distance of the pointer from the start of the thumb
+    Grab Float
+}
+
+// Keeps the offsets within the content, which may have shrunk
+func (s *ScrollState) Clamp(viewWidth, viewHeight Float) {
+    s.OffsetX = min(max(s.OffsetX, 0), max(s.ContentWidth - viewWidth, 0))
+    s.OffsetY = min(max(s.OffsetY, 0), max(s.ContentHeight - viewHeight, 0))
+}
@@ -0,0 +1,64 @@
+package ui
+
+import (
+    "dyiui/internal/lru"
+    . "dyiui/internal/types"
+)
+
+// Frames, after which the state of widgets, which were
+// not submitted during them, is dropped
+const STATE_FRAMES = 120
+
+// State of widgets, which has to survive from one frame to the
+// next, e.g. how far an area is scrolled or whether a header is
+// open. Widgets, which are not submitted anymore, do not leave
+// their state behind, it is dropped after MaxAge frames.
+type StateStore struct {
+    // STATE_FRAMES, if 0
+    MaxAge uint64
+
+    entries lru.AgingCache[ElementId, any]
+}
+
+// State of the widget, which is created with the zero value of T
+// on first use. State of another type under the same id is replaced.
+func GetState[T any](c *Context, id ElementId) *T {
+    s := &c.State
+
+    if e, ok := s.entries.Get(id); ok {
+        if value, ok := e.(*T); ok {
+            return value
+        }
+    }
+
+    value := new(T)
+    s.entries.Store(id, value)
+
+    return value
+}
+
+// Whether the widget has state, without creating it
+func (s *StateStore) Has(id ElementId) bool {
+    return s.entries.Has(id)
+}
+
+// Forgets the state of the widget, e.g. to reset it
+func (s *StateStore) Drop(id ElementId) {
+    s.entries.Delete(id)
+}
+
+// Widgets with state
+func (s *StateStore) Len() int {
+    return s.entries.Len()
+}
+
+// Advances to the next frame and drops the state
+// of widgets, which were not submitted for too long
+func (s *StateStore) NextFrame() {
+    maxAge := s.MaxAge
+    if maxAge == 0 {
+        maxAge = STATE_FRAMES
+    }
+
+    s.entries.NextFrame(maxAge)
+}
@@ -0,0 +1,52 @@
+package ui
+
+import (
+    "testing"
+)
+
+type counter struct {
+    n int
+}
+
+func TestStateSurvivesFrames(t *testing.T) {
+    c := &Context{}
+
+    GetState[counter](c, 1).n = 3
+    c.State.NextFrame()
+
+    if n := GetState[counter](c, 1).n; n != 3 {
+        t.Fatalf("Expected the state to be kept, got %d", n)
+    }
+
+    if n := GetState[counter](c, 2).n; n != 0 {
+        t.Fatalf("Expected other widgets to start with the zero value, got %d", n)
+    }
+}
+
+func TestStateOfAnotherTypeIsReplaced(t *testing.T) {
+    c := &Context{}
+
+    GetState[counter](c, 1).n = 3
+    GetState[ScrollState](c, 1).OffsetY = 10
+
+    if n := GetState[counter](c, 1).n; n != 0 {
+        t.Fatalf("Expected fresh state, got %d", n)
+    }
+}
+
+func TestUnusedStateIsDropped(t *testing.T) {
+    c := &Context{}
+    c.State.MaxAge = 2
+
+    GetState[counter](c, 1)
+    GetState[counter](c, 2)
+
+    for i := 0; i < 3; i++ {
+        c.State.NextFrame()
+        GetState[counter](c, 1)
+    }
+
+    if !c.State.Has(1) || c.State.Has(2) || c.State.Len() != 1 {
+        t.Fatalf("Expected only the state of the submitted widget to be kept")
+    }
+}
@@ -0,0 +1,148 @@
+package ui
+
+import (
+    . "dyiui/internal/types"
+    "unicode"
+    "unicode/utf8"
+)
+
+// Editing state of the focused text input. Only one input
+// can be edited at a time, so the state is shared and reset,
+// when another input gains focus.
+type TextInputState struct {
+    // input the state belongs to
+    Id ElementId
+    // byte offset of the caret
+    Caret int
+    // other end of the selection, equals Caret if nothing is selected
+    Anchor int
+    // how far the text is scrolled to the left, in pixels
+    Scroll Float
+    // set while the pointer selects text
+    Dragging bool
+}
+
+func (s *TextInputState) Reset(id ElementId) {
+    *s = TextInputState{ Id: id }
+}
+
+func (s *TextInputState) HasSelection() bool {
+    return s.Caret != s.Anchor
+}
+
+// Selected byte range, ordered
+func (s *TextInputState) Selection() (int, int) {
+    return min(s.Caret, s.Anchor), max(s.Caret, s.Anchor)
+}
+
+// Moves the caret, keeping the anchor if the selection is extended
+func (s *TextInputState) MoveTo(pos int, extend bool) {
+    s.Caret = pos
+    if !extend {
+        s.Anchor = pos
+    }
+}
+
+func (s *TextInputState) SelectAll(text string) {
+    s.Anchor = 0
+    s.Caret = len(text)
+}
+
+// Keeps caret and anchor within the text, e.g. after
+// the text was changed by someone else
+func (s *TextInputState) Clamp(text string) {
+    s.Caret = clampToRune(text, s.Caret)
+    s.Anchor = clampToRune(text, s.Anchor)
+}
+
+func clampToRune(text string, pos int) int {
+    pos = min(max(pos, 0), len(text))
+    for pos > 0 && pos < len(text) && !utf8.RuneStart(text[pos]) {
+        pos -= 1
+    }
+    return pos
+}
+
+// Replaces the selection with s and places the caret behind it
+func (s *TextInputState) Insert(text string, insert string) string {
+    start, end := s.Selection()
+    text = text[:start] + insert + text[end:]
+
+    s.MoveTo(start + len(insert), false)
+    return text
+}
+
+// Deletes the selection, or if there is none,
+// everything between the caret and pos
+func (s *TextInputState) DeleteTo(text string, pos int) string {
+    if !s.HasSelection() {
+        s.Anchor = pos
+    }
+
+    return s.Insert(text, "")
+}
+
+func PrevRune(text string, pos int) int {
+    if pos <= 0 {
+        return 0
+    }
+
+    _, size := utf8.DecodeLastRuneInString(text[:pos])
+    return pos - size
+}
+
+func NextRune(text string, pos int) int {
+    if pos >= len(text) {
+        return len(text)
+    }
+
+    _, size := utf8.DecodeRuneInString(text[pos:])
+    return pos + size
+}
+
+func isWordRune(r rune) bool {
+    return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_'
+}
+
+// Start of the word left of pos, skipping whitespace and
+// punctuation in between, like ctrl+left in most editors
+func PrevWord(text string, pos int) int {
+    for pos > 0 {
+        r, size := utf8.DecodeLastRuneInString(text[:pos])
+        if isWordRune(r) {
+            break
+        }
+        pos -= size
+    }
+
+    for pos > 0 {
+        r, size := utf8.DecodeLastRuneInString(text[:pos])
+        if !isWordRune(r) {
+            break
+        }
+        pos -= size
+    }
+
+    return pos
+}
+
+// End of the word right of pos
+func NextWord(text string, pos int) int {
+    for pos < len(text) {
+        r, size := utf8.DecodeRuneInString(text[pos:])
+        if isWordRune(r) {
+            break
+        }
+        pos += size
+    }
+
+    for pos < len(text) {
+        r, size := utf8.DecodeRuneInString(text[pos:])
+        if !isWordRune(r) {
+            break
+        }
+        pos += size
+    }
+
+    return pos
+}
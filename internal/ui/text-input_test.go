@@ -0,0 +1,83 @@
+package ui
+
+import (
+    "testing"
+)
+
+func TestWordMovement(t *testing.T) {
+    text := "hello, big world"
+
+    if p := NextWord(text, 0); p != 5 {
+        t.Fatalf("Expected end of 'hello', got %d", p)
+    }
+
+    if p := NextWord(text, 5); p != 10 {
+        t.Fatalf("Expected punctuation and space to be skipped, got %d", p)
+    }
+
+    if p := PrevWord(text, len(text)); p != 11 {
+        t.Fatalf("Expected start of 'world', got %d", p)
+    }
+
+    if p := PrevWord(text, 7); p != 0 {
+        t.Fatalf("Expected start of 'hello', got %d", p)
+    }
+}
+
+func TestRuneMovementRespectsMultibyteRunes(t *testing.T) {
+    text := "aäb"
+
+    if p := NextRune(text, 1); p != 3 {
+        t.Fatalf("Expected to skip both bytes of 'ä', got %d", p)
+    }
+
+    if p := PrevRune(text, 3); p != 1 {
+        t.Fatalf("Expected to skip both bytes of 'ä', got %d", p)
+    }
+}
+
+func TestInsertReplacesSelection(t *testing.T) {
+    var s TextInputState
+    text := "hello world"
+
+    s.MoveTo(6, false)
+    s.MoveTo(11, true)
+    text = s.Insert(text, "there")
+
+    if text != "hello there" {
+        t.Fatalf("Expected selection to be replaced, got '%s'", text)
+    }
+
+    if s.Caret != 11 || s.HasSelection() {
+        t.Fatalf("Expected caret behind the insertion without selection, got %d..%d", s.Anchor, s.Caret)
+    }
+}
+
+func TestDeleteWithoutSelection(t *testing.T) {
+    var s TextInputState
+    text := "abc"
+
+    s.MoveTo(2, false)
+    text = s.DeleteTo(text, PrevRune(text, s.Caret))
+
+    if text != "ac" || s.Caret != 1 {
+        t.Fatalf("Expected rune before the caret to be deleted, got '%s' at %d", text, s.Caret)
+    }
+}
+
+func TestClampMovesIntoRuneBoundary(t *testing.T) {
+    var s TextInputState
+    s.MoveTo(2, false)
+    s.Clamp("ä")
+
+    if s.Caret != 2 {
+        t.Fatalf("Expected end of text to stay, got %d", s.Caret)
+    }
+
+    s.MoveTo(1, false)
+    s.Clamp("ä")
+
+    if s.Caret != 0 {
+        t.Fatalf("Expected caret within a rune to move to its start, got %d", s.Caret)
+    }
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	. "dyiui/internal/ui"
+
+	"github.com/go-gl/glfw/v3.3/glfw"
+)
+
+// Maps glfw's key codes onto the ones widgets use
+var keyMap = map[glfw.Key]Key{
+	glfw.KeySpace:        KeySpace,
+	glfw.KeyEnter:        KeyEnter,
+	glfw.KeyKPEnter:      KeyEnter,
+	glfw.KeyTab:          KeyTab,
+	glfw.KeyBackspace:    KeyBackspace,
+	glfw.KeyDelete:       KeyDelete,
+	glfw.KeyInsert:       KeyInsert,
+	glfw.KeyEscape:       KeyEscape,
+	glfw.KeyLeft:         KeyLeft,
+	glfw.KeyRight:        KeyRight,
+	glfw.KeyUp:           KeyUp,
+	glfw.KeyDown:         KeyDown,
+	glfw.KeyHome:         KeyHome,
+	glfw.KeyEnd:          KeyEnd,
+	glfw.KeyPageUp:       KeyPageUp,
+	glfw.KeyPageDown:     KeyPageDown,
+	glfw.KeyLeftShift:    KeyLeftShift,
+	glfw.KeyRightShift:   KeyRightShift,
+	glfw.KeyLeftControl:  KeyLeftControl,
+	glfw.KeyRightControl: KeyRightControl,
+	glfw.KeyLeftAlt:      KeyLeftAlt,
+	glfw.KeyRightAlt:     KeyRightAlt,
+	glfw.KeyLeftSuper:    KeyLeftSuper,
+	glfw.KeyRightSuper:   KeyRightSuper,
+	glfw.Key0:            Key0,
+	glfw.Key1:            Key1,
+	glfw.Key2:            Key2,
+	glfw.Key3:            Key3,
+	glfw.Key4:            Key4,
+	glfw.Key5:            Key5,
+	glfw.Key6:            Key6,
+	glfw.Key7:            Key7,
+	glfw.Key8:            Key8,
+	glfw.Key9:            Key9,
+	glfw.KeyA:            KeyA,
+	glfw.KeyB:            KeyB,
+	glfw.KeyC:            KeyC,
+	glfw.KeyD:            KeyD,
+	glfw.KeyE:            KeyE,
+	glfw.KeyF:            KeyF,
+	glfw.KeyG:            KeyG,
+	glfw.KeyH:            KeyH,
+	glfw.KeyI:            KeyI,
+	glfw.KeyJ:            KeyJ,
+	glfw.KeyK:            KeyK,
+	glfw.KeyL:            KeyL,
+	glfw.KeyM:            KeyM,
+	glfw.KeyN:            KeyN,
+	glfw.KeyO:            KeyO,
+	glfw.KeyP:            KeyP,
+	glfw.KeyQ:            KeyQ,
+	glfw.KeyR:            KeyR,
+	glfw.KeyS:            KeyS,
+	glfw.KeyT:            KeyT,
+	glfw.KeyU:            KeyU,
+	glfw.KeyV:            KeyV,
+	glfw.KeyW:            KeyW,
+	glfw.KeyX:            KeyX,
+	glfw.KeyY:            KeyY,
+	glfw.KeyZ:            KeyZ,
+	glfw.KeyF1:           KeyF1,
+	glfw.KeyF2:           KeyF2,
+	glfw.KeyF3:           KeyF3,
+	glfw.KeyF4:           KeyF4,
+	glfw.KeyF5:           KeyF5,
+	glfw.KeyF6:           KeyF6,
+	glfw.KeyF7:           KeyF7,
+	glfw.KeyF8:           KeyF8,
+	glfw.KeyF9:           KeyF9,
+	glfw.KeyF10:          KeyF10,
+	glfw.KeyF11:          KeyF11,
+	glfw.KeyF12:          KeyF12,
+}
+
+func mapKey(key glfw.Key) Key {
+	if k, ok := keyMap[key]; ok {
+		return k
+	}
+
+	return KeyUnknown
+}
+
+func mapMouseButton(button glfw.MouseButton) MouseButton {
+	switch button {
+	case glfw.MouseButtonLeft:
+		return MouseLeft
+	case glfw.MouseButtonRight:
+		return MouseRight
+	case glfw.MouseButtonMiddle:
+		return MouseMiddle
+	case glfw.MouseButton4:
+		return MouseExtra1
+	case glfw.MouseButton5:
+		return MouseExtra2
+	default:
+		// ignored by the pointer state
+		return -1
+	}
+}
+
+func mapKeyAction(action glfw.Action) KeyAction {
+	switch action {
+	case glfw.Press:
+		return KeyPress
+	case glfw.Repeat:
+		return KeyRepeat
+	default:
+		return KeyRelease
+	}
+}
+
+func mapModifiers(mods glfw.ModifierKey) Modifiers {
+	var m Modifiers
+
+	if mods&glfw.ModShift != 0 {
+		m |= ModShift
+	}
+	if mods&glfw.ModControl != 0 {
+		m |= ModControl
+	}
+	if mods&glfw.ModAlt != 0 {
+		m |= ModAlt
+	}
+	if mods&glfw.ModSuper != 0 {
+		m |= ModSuper
+	}
+
+	return m
+}
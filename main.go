@@ -4,6 +4,7 @@ import (
 	. "dyiui/internal/gl"
 	. "dyiui/internal/layout"
 	. "dyiui/internal/ui"
+	"flag"
 	"runtime"
 	"time"
 
@@ -16,10 +17,21 @@ const USE_DEBUG_UV = false
 const DEBUG_SHADERS = true
 const DEBUG = true
 
+var headless = flag.String("headless", "", "render a single frame with the software backend into the given png")
+
 func init() {
 	runtime.LockOSThread() }
 
 func main() {
+	flag.Parse()
+
+	if *headless != "" {
+		err := RenderHeadless(*headless, WIN_WIDTH, WIN_HEIGHT)
+		if err != nil {
+			panic(err)
+		}
+		return
+	}
 
 	err := glfw.Init()
 	if err != nil {
@@ -29,6 +41,7 @@ func main() {
 
 	app := createApp()
 	app.Init(WIN_WIDTH, WIN_HEIGHT, WIN_NAME)
+	defer app.renderer.Fonts.Close()
 
 	app.Loop()
 }
@@ -61,20 +74,52 @@ func (a *App) Init(width int, height int, name string) {
         Width: WIN_WIDTH,
         Height: WIN_HEIGHT,
         PointerState: NewPointerState(),
+        Keyboard: NewKeyboardState(),
     }
+
+    // events arrive during PollEvents and are
+    // applied with the beginning of the next frame
+    window.SetKeyCallback(func(w *glfw.Window, key glfw.Key, scancode int, action glfw.Action, mods glfw.ModifierKey) {
+        a.context.Keyboard.QueueKey(mapKey(key), mapKeyAction(action), mapModifiers(mods))
+    })
+
+    window.SetCharCallback(func(w *glfw.Window, char rune) {
+        a.context.Keyboard.QueueRune(char)
+    })
+
+    window.SetScrollCallback(func(w *glfw.Window, xoff float64, yoff float64) {
+        a.context.PointerState.QueueWheel(float32(xoff), float32(yoff))
+    })
+
+    window.SetCursorPosCallback(func(w *glfw.Window, x float64, y float64) {
+        a.context.PointerState.QueueMove(float32(x), float32(y))
+    })
+
+    window.SetMouseButtonCallback(func(w *glfw.Window, button glfw.MouseButton, action glfw.Action, mods glfw.ModifierKey) {
+        a.context.PointerState.QueueButton(mapMouseButton(button), action != glfw.Release, time.Now())
+    })
+
+    window.SetCursorEnterCallback(func(w *glfw.Window, entered bool) {
+        a.context.PointerState.QueueEnter(entered)
+    })
+
+    // the position is only reported, once the pointer moves
+    x, y := window.GetCursorPos()
+    a.context.PointerState.QueueMove(float32(x), float32(y))
 }
 
 func (a *App) Loop() {
 	for !a.window.ShouldClose() {
 
-        a.UpdatePointerState()
+        a.context.PointerState.NewFrame()
+        a.context.Keyboard.NewFrame()
 
-        BeginFrame()
+        a.renderer.BeginFrame()
         ui := NewUI(a.context, a.renderer)
 
         RenderUI(&ui)
 
-        FinishFrame()
+        a.renderer.FinishFrame(ui.DrawList)
 
         // Push to display
         a.window.SwapBuffers()
@@ -85,29 +130,3 @@ func (a *App) Loop() {
         time.Sleep(time.Millisecond * 3)
 	}
 }
-
-func (a *App) UpdatePointerState() {
-    ev := a.window.GetMouseButton(glfw.MouseButtonLeft)
-
-    s := &a.context.PointerState
-
-    if ev == glfw.Press {
-        s.JustActivated = !s.Active
-        s.Active = true
-        s.JustReleased = false
-    } else if ev == glfw.Repeat {
-        s.Active = true
-        s.JustReleased = false
-        s.JustActivated = false
-    } else {
-        s.JustReleased = s.Active
-        s.Active = false
-        s.JustActivated = false
-    }
-
-    x, y := a.window.GetCursorPos()
-
-    s.PosX = float32(x)
-    s.PosY = float32(y)
-}
-